@@ -0,0 +1,162 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+func sine(freq float64, rate, n int, amplitude float64) []int16 {
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return out
+}
+
+func rms(samples []int16) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestG711RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []int16{0, 1, -1, 100, -100, 1000, -1000, 12345, -12345, math.MaxInt16, math.MinInt16} {
+		if got := MulawDecode(MulawEncode(s)); math.Abs(float64(got-s)) > math.Max(8, math.Abs(float64(s))/16) {
+			t.Errorf("μ-law round trip of %d = %d", s, got)
+		}
+		if got := AlawDecode(AlawEncode(s)); math.Abs(float64(got-s)) > math.Max(16, math.Abs(float64(s))/16) {
+			t.Errorf("A-law round trip of %d = %d", s, got)
+		}
+	}
+	if got, want := MulawEncode(0), byte(0xff); got != want {
+		t.Errorf("MulawEncode(0) = %#x, want %#x", got, want)
+	}
+	if got, want := AlawEncode(0), byte(0xd5); got != want {
+		t.Errorf("AlawEncode(0) = %#x, want %#x", got, want)
+	}
+}
+
+func TestResamplePreservesToneAndLength(t *testing.T) {
+	t.Parallel()
+
+	for _, quality := range []Quality{QualityLow, QualityMedium, QualityHigh} {
+		in := sine(440, 48000, 48000, 10000)
+		out := Resample(in, 48000, 16000, 1, quality)
+		if got, want := len(out), 16000; got != want {
+			t.Fatalf("quality %d: len = %d, want %d", quality, got, want)
+		}
+		if got := rms(out[1000:15000]); math.Abs(got-rms(in)) > 300 {
+			t.Errorf("quality %d: rms = %.0f, want about %.0f", quality, got, rms(in))
+		}
+	}
+}
+
+func TestResamplerFiltersAliasing(t *testing.T) {
+	t.Parallel()
+
+	// 7 kHz is above the 4 kHz Nyquist frequency of the target rate.
+	in := sine(7000, 48000, 48000, 10000)
+	if got := rms(Resample(in, 48000, 8000, 1, QualityHigh)[500:7500]); got > 200 {
+		t.Errorf("QualityHigh alias rms = %.0f, want < 200", got)
+	}
+	if got := rms(Resample(in, 48000, 8000, 1, QualityLow)[500:7500]); got < 1000 {
+		t.Errorf("QualityLow alias rms = %.0f, expected aliasing", got)
+	}
+}
+
+func TestResamplerChunkedMatchesWhole(t *testing.T) {
+	t.Parallel()
+
+	in := sine(300, 44100, 44100, 8000)
+	want := Resample(in, 44100, 16000, 1, QualityMedium)
+
+	r := NewResampler(44100, 16000, 1, QualityMedium)
+	var got []int16
+	for rest := in; len(rest) > 0; {
+		n := min(len(rest), 997)
+		got = append(got, r.Process(rest[:n])...)
+		rest = rest[n:]
+	}
+	got = append(got, r.Flush()...)
+	if len(got) != len(want) {
+		t.Fatalf("len = %d, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("sample %d = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestResamplerPanicsOnInvalidRates(t *testing.T) {
+	t.Parallel()
+
+	for _, rates := range [][2]int{{0, 0}, {0, 16000}, {16000, -8000}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Resample(%d Hz to %d Hz) did not panic", rates[0], rates[1])
+				}
+			}()
+			Resample([]int16{1, 2, 3}, rates[0], rates[1], 1, QualityLow)
+		}()
+	}
+}
+
+func TestConverterStereo48kToTranscriptsInput(t *testing.T) {
+	t.Parallel()
+
+	mono := sine(440, 48000, 4800, 10000)
+	stereo := EncodePCM16(UpmixFromMono(mono, 2))
+
+	to, err := FromTranscripts(transcripts.AudioFormatPcm_16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent bytes.Buffer
+	w, err := NewWriter(SendFunc(func(data []byte) error {
+		sent.Write(data)
+		return nil
+	}), Format{Encoding: EncodingPCM16, SampleRate: 48000, Channels: 2}, to, QualityMedium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Odd-sized writes split frames.
+	for rest := stereo; len(rest) > 0; {
+		n := min(len(rest), 333)
+		if _, err := w.Write(rest[:n]); err != nil {
+			t.Fatal(err)
+		}
+		rest = rest[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sent.Len(), 1600*2; got != want {
+		t.Errorf("converted bytes = %d, want %d", got, want)
+	}
+}
+
+func TestFromTTS(t *testing.T) {
+	t.Parallel()
+
+	got, err := FromTTS(tts.AudioFormatALAW8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Format{Encoding: EncodingAlaw, SampleRate: 8000, Channels: 1}); got != want {
+		t.Errorf("FromTTS() = %+v, want %+v", got, want)
+	}
+	if _, err := FromTTS(tts.AudioFormatMP344100128); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("FromTTS(mp3) error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
@@ -0,0 +1,209 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readSampleMP3(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../examples/simple/nicole.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMP3DurationMatchesPCMRendition(t *testing.T) {
+	t.Parallel()
+
+	info, err := ReadMP3Info(bytes.NewReader(readSampleMP3(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SampleRate != 44100 || info.Channels != 1 {
+		t.Errorf("format = %d Hz, %d channels, want 44100 Hz mono", info.SampleRate, info.Channels)
+	}
+	// nicole.pcm is the same speech as 16 kHz PCM.
+	pcm, err := os.Stat("../examples/simple/nicole.pcm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Duration(pcm.Size()/2) * time.Second / 16000
+	if diff := info.Duration - want; diff < -30*time.Millisecond || diff > 30*time.Millisecond {
+		t.Errorf("Duration = %v, want about %v", info.Duration, want)
+	}
+}
+
+func TestSplitAndConcatMP3(t *testing.T) {
+	t.Parallel()
+
+	data := readSampleMP3(t)
+	want, err := MP3Duration(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks [][]byte
+	if err := SplitMP3(bytes.NewReader(data), time.Second, func(chunk []byte) error {
+		chunks = append(chunks, chunk)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(chunks); got < 14 || got > 16 {
+		t.Fatalf("len(chunks) = %d, want about 15", got)
+	}
+	for i, chunk := range chunks {
+		if _, err := ParseMP3Header(chunk); err != nil {
+			t.Fatalf("chunk %d does not start on a frame: %v", i, err)
+		}
+	}
+
+	var joined bytes.Buffer
+	if err := ConcatMP3(&joined, bytes.NewReader(data), bytes.NewReader(bytes.Join(chunks, nil))); err != nil {
+		t.Fatal(err)
+	}
+	got, err := MP3Duration(joined.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2*want {
+		t.Errorf("concatenated duration = %v, want %v", got, 2*want)
+	}
+}
+
+func TestMP3ReaderSkipsFalseSyncs(t *testing.T) {
+	t.Parallel()
+
+	data := readSampleMP3(t)
+	want, err := ReadMP3Info(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A valid MPEG-1 layer III header that is not followed by another frame.
+	junk := append([]byte{0xff, 0xfb, 0x90, 0x64}, make([]byte, 20)...)
+	got, err := ReadMP3Info(bytes.NewReader(append(junk, data...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Frames != want.Frames || got.Samples != want.Samples {
+		t.Errorf("ReadMP3Info() = %d frames, %d samples, want %d frames, %d samples", got.Frames, got.Samples, want.Frames, want.Samples)
+	}
+}
+
+func TestConcatMP3RejectsMismatchedClips(t *testing.T) {
+	t.Parallel()
+
+	// A single MPEG-1 layer III frame at 48 kHz.
+	frame := make([]byte, 144*128000/48000)
+	copy(frame, []byte{0xff, 0xfb, 0x94, 0xc4})
+	err := ConcatMP3(&bytes.Buffer{}, bytes.NewReader(readSampleMP3(t)), bytes.NewReader(frame))
+	if !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("ConcatMP3() error = %v, want %v", err, ErrFormatMismatch)
+	}
+}
+
+// testOpusStream builds an Ogg/Opus stream with 20 ms CELT packets.
+func testOpusStream(serial uint32, packets, perPage, preSkip int) []byte {
+	head := []byte("OpusHead\x01\x01\x00\x00\x80\xbb\x00\x00\x00\x00\x00")
+	head[10], head[11] = byte(preSkip), byte(preSkip>>8)
+	pages := []OggPage{
+		{HeaderType: oggHeaderBOS, Serial: serial, Segments: []byte{byte(len(head))}, Body: head},
+		{Serial: serial, Sequence: 1, Segments: []byte{12}, Body: []byte("OpusTags\x00\x00\x00\x00")},
+	}
+	var granule int64
+	for sent := 0; sent < packets; {
+		page := OggPage{Serial: serial, Sequence: uint32(len(pages))}
+		for i := 0; i < perPage && sent < packets; i++ {
+			page.Segments = append(page.Segments, 3)
+			page.Body = append(page.Body, 31<<3, 0xaa, 0xbb)
+			granule += 960
+			sent++
+		}
+		page.Granule = granule
+		pages = append(pages, page)
+	}
+	pages[len(pages)-1].HeaderType |= oggHeaderEOS
+	var out []byte
+	for _, page := range pages {
+		out = append(out, page.Bytes()...)
+	}
+	return out
+}
+
+func TestOggOpusDurationSplitAndConcat(t *testing.T) {
+	t.Parallel()
+
+	clip := testOpusStream(7, 100, 10, 312)
+	got, err := OggOpusDuration(clip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Duration(100*960-312) * time.Second / 48000; got != want {
+		t.Errorf("OggOpusDuration() = %v, want %v", got, want)
+	}
+
+	var chunks [][]byte
+	if err := SplitOggOpus(bytes.NewReader(clip), 500*time.Millisecond, func(chunk []byte) error {
+		chunks = append(chunks, chunk)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(chunks), 4; got != want {
+		t.Errorf("len(chunks) = %d, want %d", got, want)
+	}
+	if !bytes.Equal(bytes.Join(chunks, nil), clip) {
+		t.Error("chunks do not reassemble to the original stream")
+	}
+
+	var joined bytes.Buffer
+	if err := ConcatOggOpus(&joined, bytes.NewReader(clip), bytes.NewReader(testOpusStream(9, 50, 10, 312))); err != nil {
+		t.Fatal(err)
+	}
+	info, err := ReadOggOpusInfo(bytes.NewReader(joined.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(150*960 - 2*312); info.Samples != want {
+		t.Errorf("concatenated Samples = %d, want %d", info.Samples, want)
+	}
+	if got, want := info.Pages, 2+10+2+5; got != want {
+		t.Errorf("Pages = %d, want %d", got, want)
+	}
+
+	reader := NewOggReader(bytes.NewReader(joined.Bytes()))
+	var links []string
+	for {
+		page, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.BOS() || page.EOS() {
+			links = append(links, fmt.Sprintf("%d:%d:%t", page.Serial, page.Sequence, page.BOS()))
+		}
+	}
+	if got, want := strings.Join(links, " "), "7:0:true 7:11:false 8:0:true 8:6:false"; got != want {
+		t.Errorf("link boundaries = %s, want %s", got, want)
+	}
+}
+
+func TestOggReaderRejectsCorruptPage(t *testing.T) {
+	t.Parallel()
+
+	clip := testOpusStream(1, 10, 10, 0)
+	clip[len(clip)-1] ^= 0xff
+	if _, err := ReadOggOpusInfo(bytes.NewReader(clip)); !errors.Is(err, ErrInvalidOgg) {
+		t.Errorf("ReadOggOpusInfo() error = %v, want %v", err, ErrInvalidOgg)
+	}
+}
@@ -0,0 +1,114 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+)
+
+// Converter converts a byte stream from one Format to another.
+// Partial frames are held until the next call. A Converter is not safe for concurrent use.
+type Converter struct {
+	from, to  Format
+	resampler *Resampler
+	pending   []byte
+}
+
+// NewConverter creates a Converter. Channel counts must match unless one side is mono.
+func NewConverter(from, to Format, quality Quality) (*Converter, error) {
+	if err := from.validate(); err != nil {
+		return nil, fmt.Errorf("source format: %w", err)
+	}
+	if err := to.validate(); err != nil {
+		return nil, fmt.Errorf("target format: %w", err)
+	}
+	if from.channels() != to.channels() && from.channels() != 1 && to.channels() != 1 {
+		return nil, fmt.Errorf("cannot convert %d channels to %d", from.channels(), to.channels())
+	}
+	c := &Converter{from: from, to: to}
+	if from.SampleRate != to.SampleRate {
+		c.resampler = NewResampler(from.SampleRate, to.SampleRate, min(from.channels(), to.channels()), quality)
+	}
+	return c, nil
+}
+
+// Convert converts the next chunk of the stream.
+func (c *Converter) Convert(data []byte) []byte {
+	c.pending = append(c.pending, data...)
+	whole := len(c.pending) - len(c.pending)%c.from.FrameSize()
+	samples := Decode(c.pending[:whole], c.from.Encoding)
+	c.pending = append(c.pending[:0], c.pending[whole:]...)
+
+	if c.to.channels() < c.from.channels() {
+		samples = DownmixToMono(samples, c.from.channels())
+	}
+	if c.resampler != nil {
+		samples = c.resampler.Process(samples)
+	}
+	return c.finish(samples)
+}
+
+// Flush returns the converted remainder of the stream. A trailing partial frame is dropped.
+func (c *Converter) Flush() []byte {
+	c.pending = c.pending[:0]
+	if c.resampler == nil {
+		return nil
+	}
+	return c.finish(c.resampler.Flush())
+}
+
+func (c *Converter) finish(samples []int16) []byte {
+	if c.to.channels() > c.from.channels() {
+		samples = UpmixFromMono(samples, c.to.channels())
+	}
+	return Encode(samples, c.to.Encoding)
+}
+
+// Writer converts everything written to it and writes the result to an underlying writer.
+// Use it in front of transcripts.AudioWriter, or wrap Recognizer.Send with SendFunc.
+type Writer struct {
+	w         io.Writer
+	converter *Converter
+}
+
+// NewWriter creates a Writer that converts from one format to another before writing to w.
+func NewWriter(w io.Writer, from, to Format, quality Quality) (*Writer, error) {
+	converter, err := NewConverter(from, to, quality)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, converter: converter}, nil
+}
+
+// Write converts p and writes the result.
+func (w *Writer) Write(p []byte) (int, error) {
+	if out := w.converter.Convert(p); len(out) > 0 {
+		if _, err := w.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close writes the rest of the stream and closes the underlying writer if it is an io.Closer.
+func (w *Writer) Close() error {
+	if out := w.converter.Flush(); len(out) > 0 {
+		if _, err := w.w.Write(out); err != nil {
+			return err
+		}
+	}
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SendFunc adapts a send function such as transcripts.Recognizer.Send to an io.Writer.
+type SendFunc func(data []byte) error
+
+// Write calls f with p.
+func (f SendFunc) Write(p []byte) (int, error) {
+	if err := f(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,59 @@
+package audio
+
+import "github.com/gouyuwang/go-elevenlabs/internal/g711"
+
+// MulawEncode encodes a 16-bit linear sample as G.711 μ-law.
+func MulawEncode(sample int16) byte {
+	return g711.MulawEncode(sample)
+}
+
+// MulawDecode decodes a G.711 μ-law byte to a 16-bit linear sample.
+func MulawDecode(u byte) int16 {
+	return g711.MulawDecode(u)
+}
+
+// AlawEncode encodes a 16-bit linear sample as G.711 A-law.
+func AlawEncode(sample int16) byte {
+	return g711.AlawEncode(sample)
+}
+
+// AlawDecode decodes a G.711 A-law byte to a 16-bit linear sample.
+func AlawDecode(a byte) int16 {
+	return g711.AlawDecode(a)
+}
+
+// EncodeMulaw encodes samples as μ-law bytes.
+func EncodeMulaw(samples []int16) []byte {
+	out := make([]byte, len(samples))
+	for i, s := range samples {
+		out[i] = MulawEncode(s)
+	}
+	return out
+}
+
+// DecodeMulaw decodes μ-law bytes to samples.
+func DecodeMulaw(data []byte) []int16 {
+	out := make([]int16, len(data))
+	for i, b := range data {
+		out[i] = MulawDecode(b)
+	}
+	return out
+}
+
+// EncodeAlaw encodes samples as A-law bytes.
+func EncodeAlaw(samples []int16) []byte {
+	out := make([]byte, len(samples))
+	for i, s := range samples {
+		out[i] = AlawEncode(s)
+	}
+	return out
+}
+
+// DecodeAlaw decodes A-law bytes to samples.
+func DecodeAlaw(data []byte) []int16 {
+	out := make([]int16, len(data))
+	for i, b := range data {
+		out[i] = AlawDecode(b)
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/internal/mp3"
+)
+
+// ErrInvalidMP3 is returned when a stream contains no MP3 frames.
+var ErrInvalidMP3 = mp3.ErrInvalid
+
+// MP3Header is a decoded MPEG audio frame header. FrameSize, Samples and Duration describe the frame.
+type MP3Header = mp3.Header
+
+// ParseMP3Header decodes the four header bytes of an MPEG audio frame.
+// Free-format bitrates are not supported.
+func ParseMP3Header(b []byte) (MP3Header, error) {
+	return mp3.ParseHeader(b)
+}
+
+// MP3Frame is one undecoded MPEG audio frame. IsInfo reports whether it is a Xing, Info or VBRI
+// tag frame instead of audio.
+type MP3Frame = mp3.Frame
+
+// MP3Reader reads the frames of an MP3 stream without decoding them.
+// ID3 tags and bytes that do not belong to a frame are skipped. A frame header is only accepted
+// when the next frame header, a tag or the end of the stream follows the frame.
+type MP3Reader = mp3.Reader
+
+// NewMP3Reader creates an MP3Reader.
+func NewMP3Reader(r io.Reader) *MP3Reader {
+	return mp3.NewReader(r)
+}
+
+// MP3Info summarizes an MP3 stream.
+type MP3Info struct {
+	SampleRate int
+	Channels   int
+	// Frames counts audio frames. Xing, Info and VBRI tag frames are not included.
+	Frames int
+	// Samples is the number of samples per channel, without the encoder delay and padding
+	// recorded in a LAME tag.
+	Samples        int64
+	EncoderDelay   int
+	EncoderPadding int
+	Duration       time.Duration
+}
+
+// ReadMP3Info reads the whole stream and returns its exact length.
+func ReadMP3Info(r io.Reader) (MP3Info, error) {
+	var info MP3Info
+	reader := NewMP3Reader(r)
+	for {
+		frame, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return MP3Info{}, err
+		}
+		if frame.IsInfo() {
+			if delay, padding, ok := frame.Gapless(); ok {
+				info.EncoderDelay, info.EncoderPadding = delay, padding
+			}
+			continue
+		}
+		if info.Frames == 0 {
+			info.SampleRate, info.Channels = frame.Header.SampleRate, frame.Header.Channels
+		}
+		info.Frames++
+		info.Samples += int64(frame.Header.Samples())
+	}
+	if info.Frames == 0 {
+		return MP3Info{}, fmt.Errorf("%w: no audio frames", ErrInvalidMP3)
+	}
+	info.Samples = max(0, info.Samples-int64(info.EncoderDelay+info.EncoderPadding))
+	info.Duration = time.Duration(info.Samples * int64(time.Second) / int64(info.SampleRate))
+	return info, nil
+}
+
+// MP3Duration returns the exact duration of an MP3 file, such as tts.SynthesisResponse.Audio.
+func MP3Duration(data []byte) (time.Duration, error) {
+	info, err := ReadMP3Info(bytes.NewReader(data))
+	return info.Duration, err
+}
+
+// SplitMP3 reads an MP3 stream, such as tts.StreamResponse.Audio, and calls emit with runs of whole frames
+// of about chunk duration each. Tags are dropped, so the chunks concatenate to a clean stream.
+func SplitMP3(r io.Reader, chunk time.Duration, emit func(data []byte) error) error {
+	reader := NewMP3Reader(r)
+	var (
+		buf      []byte
+		buffered time.Duration
+	)
+	for {
+		frame, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		buf = append(buf, frame.Data...)
+		buffered += frame.Header.Duration()
+		if buffered >= chunk {
+			if err := emit(buf); err != nil {
+				return err
+			}
+			buf, buffered = nil, 0
+		}
+	}
+	if len(buf) > 0 {
+		return emit(buf)
+	}
+	return nil
+}
+
+// ConcatMP3 writes the audio frames of several MP3 clips to w as one stream.
+// Tags and Xing/Info frames are dropped, since a tag frame in the middle of a stream plays as a gap.
+// All clips must share a sample rate and channel count.
+func ConcatMP3(w io.Writer, clips ...io.Reader) error {
+	return mp3.Concat(w, ErrFormatMismatch, clips...)
+}
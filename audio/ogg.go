@@ -0,0 +1,305 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrInvalidOgg is returned for malformed Ogg streams or Ogg streams that do not carry Opus.
+var ErrInvalidOgg = errors.New("invalid Ogg data")
+
+const (
+	oggHeaderBOS = 0x02
+	oggHeaderEOS = 0x04
+	// opusSampleRate is the clock of Ogg/Opus granule positions, whatever the input rate was.
+	opusSampleRate = 48000
+)
+
+// OggPage is one page of an Ogg stream.
+type OggPage struct {
+	HeaderType byte
+	// Granule is the granule position after the last packet that ends on this page, or -1 if none does.
+	Granule  int64
+	Serial   uint32
+	Sequence uint32
+	// Segments is the lacing table.
+	Segments []byte
+	Body     []byte
+}
+
+// BOS reports whether the page begins a logical stream.
+func (p OggPage) BOS() bool { return p.HeaderType&oggHeaderBOS != 0 }
+
+// EOS reports whether the page ends a logical stream.
+func (p OggPage) EOS() bool { return p.HeaderType&oggHeaderEOS != 0 }
+
+// Bytes encodes the page with a fresh checksum.
+func (p OggPage) Bytes() []byte {
+	out := make([]byte, 27+len(p.Segments)+len(p.Body))
+	copy(out, "OggS")
+	out[5] = p.HeaderType
+	binary.LittleEndian.PutUint64(out[6:], uint64(p.Granule))
+	binary.LittleEndian.PutUint32(out[14:], p.Serial)
+	binary.LittleEndian.PutUint32(out[18:], p.Sequence)
+	out[26] = byte(len(p.Segments))
+	copy(out[27:], p.Segments)
+	copy(out[27+len(p.Segments):], p.Body)
+	binary.LittleEndian.PutUint32(out[22:], oggChecksum(out))
+	return out
+}
+
+// OggReader reads the pages of an Ogg stream without decoding their packets.
+type OggReader struct {
+	r *bufio.Reader
+}
+
+// NewOggReader creates an OggReader.
+func NewOggReader(r io.Reader) *OggReader {
+	return &OggReader{r: bufio.NewReaderSize(r, 8192)}
+}
+
+// Next returns the next page, or io.EOF at the end of the stream.
+// Pages with a bad checksum return ErrInvalidOgg.
+func (r *OggReader) Next() (OggPage, error) {
+	header := make([]byte, 27)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			return OggPage{}, io.EOF
+		}
+		return OggPage{}, fmt.Errorf("%w: truncated page header", ErrInvalidOgg)
+	}
+	if string(header[:4]) != "OggS" || header[4] != 0 {
+		return OggPage{}, fmt.Errorf("%w: missing capture pattern", ErrInvalidOgg)
+	}
+	page := OggPage{
+		HeaderType: header[5],
+		Granule:    int64(binary.LittleEndian.Uint64(header[6:])),
+		Serial:     binary.LittleEndian.Uint32(header[14:]),
+		Sequence:   binary.LittleEndian.Uint32(header[18:]),
+		Segments:   make([]byte, header[26]),
+	}
+	if _, err := io.ReadFull(r.r, page.Segments); err != nil {
+		return OggPage{}, fmt.Errorf("%w: truncated lacing table", ErrInvalidOgg)
+	}
+	var size int
+	for _, lacing := range page.Segments {
+		size += int(lacing)
+	}
+	page.Body = make([]byte, size)
+	if _, err := io.ReadFull(r.r, page.Body); err != nil {
+		return OggPage{}, fmt.Errorf("%w: truncated page body", ErrInvalidOgg)
+	}
+	if got, want := page.Bytes(), binary.LittleEndian.Uint32(header[22:]); binary.LittleEndian.Uint32(got[22:]) != want {
+		return OggPage{}, fmt.Errorf("%w: checksum mismatch on page %d", ErrInvalidOgg, page.Sequence)
+	}
+	return page, nil
+}
+
+// OpusInfo summarizes an Ogg/Opus stream.
+type OpusInfo struct {
+	Channels int
+	// PreSkip is the number of 48 kHz priming samples at the start of each logical stream.
+	PreSkip int
+	// Samples is the number of 48 kHz samples per channel after pre-skip and end trimming.
+	Samples  int64
+	Pages    int
+	Duration time.Duration
+}
+
+// ReadOggOpusInfo reads the whole stream and returns its exact length from the granule positions.
+// Chained streams are summed.
+func ReadOggOpusInfo(r io.Reader) (OpusInfo, error) {
+	var (
+		info    OpusInfo
+		last    int64
+		preSkip int
+		inLink  bool
+	)
+	reader := NewOggReader(r)
+	for {
+		page, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return OpusInfo{}, err
+		}
+		info.Pages++
+		if page.BOS() {
+			if inLink {
+				info.Samples += max(0, last-int64(preSkip))
+			}
+			channels, skip, err := parseOpusHead(page.Body)
+			if err != nil {
+				return OpusInfo{}, err
+			}
+			if info.Channels == 0 {
+				info.Channels, info.PreSkip = channels, skip
+			}
+			preSkip, last, inLink = skip, 0, true
+			continue
+		}
+		if !inLink {
+			return OpusInfo{}, fmt.Errorf("%w: stream does not start with OpusHead", ErrInvalidOgg)
+		}
+		if page.Granule >= 0 {
+			last = page.Granule
+		}
+	}
+	if !inLink {
+		return OpusInfo{}, fmt.Errorf("%w: empty stream", ErrInvalidOgg)
+	}
+	info.Samples += max(0, last-int64(preSkip))
+	info.Duration = time.Duration(info.Samples * int64(time.Second) / opusSampleRate)
+	return info, nil
+}
+
+// OggOpusDuration returns the exact duration of an Ogg/Opus file, such as tts.SynthesisResponse.Audio.
+func OggOpusDuration(data []byte) (time.Duration, error) {
+	info, err := ReadOggOpusInfo(bytes.NewReader(data))
+	return info.Duration, err
+}
+
+func parseOpusHead(body []byte) (channels, preSkip int, err error) {
+	if len(body) < 19 || string(body[:8]) != "OpusHead" {
+		return 0, 0, fmt.Errorf("%w: missing OpusHead", ErrInvalidOgg)
+	}
+	return int(body[9]), int(binary.LittleEndian.Uint16(body[10:])), nil
+}
+
+// SplitOggOpus reads an Ogg/Opus stream, such as tts.StreamResponse.Audio, and calls emit with runs of
+// whole pages of about chunk duration each. The header pages go out with the first chunk.
+func SplitOggOpus(r io.Reader, chunk time.Duration, emit func(data []byte) error) error {
+	reader := NewOggReader(r)
+	var (
+		buf      []byte
+		buffered int64
+		last     int64
+	)
+	limit := int64(chunk) * opusSampleRate / int64(time.Second)
+	for {
+		page, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		buf = append(buf, page.Bytes()...)
+		if page.BOS() {
+			last = 0
+		}
+		if page.Granule <= 0 {
+			continue
+		}
+		buffered += page.Granule - last
+		last = page.Granule
+		if buffered >= limit {
+			if err := emit(buf); err != nil {
+				return err
+			}
+			buf, buffered = nil, 0
+		}
+	}
+	if len(buf) > 0 {
+		return emit(buf)
+	}
+	return nil
+}
+
+// ConcatOggOpus writes several Ogg/Opus clips to w as a chained Ogg stream.
+//
+// Every clip becomes a logical stream of its own, with its header pages, so that decoders apply the
+// pre-skip of each clip and its priming samples do not play at the seam. The links get consecutive
+// serial numbers from the first clip's, and each one ends with an end-of-stream page.
+// All clips must have the same channel count.
+func ConcatOggOpus(w io.Writer, clips ...io.Reader) error {
+	var (
+		serial   uint32
+		channels int
+		pending  *OggPage
+	)
+	// Pages are written one behind so that the last page of each clip can be marked as the end of its stream.
+	flush := func(eos bool) error {
+		if pending == nil {
+			return nil
+		}
+		if eos {
+			pending.HeaderType |= oggHeaderEOS
+		}
+		_, err := w.Write(pending.Bytes())
+		pending = nil
+		return err
+	}
+
+	for i, clip := range clips {
+		reader := NewOggReader(clip)
+		for index := 0; ; index++ {
+			page, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("clip %d: %w", i, err)
+			}
+			if index == 0 {
+				clipChannels, _, err := parseOpusHead(page.Body)
+				if err != nil {
+					return fmt.Errorf("clip %d: %w", i, err)
+				}
+				if i == 0 {
+					serial, channels = page.Serial, clipChannels
+				} else if clipChannels != channels {
+					return fmt.Errorf("%w: clip %d has %d channels, want %d", ErrFormatMismatch, i, clipChannels, channels)
+				}
+			}
+			if err := flush(false); err != nil {
+				return err
+			}
+			page.Serial = serial + uint32(i)
+			page.Sequence = uint32(index)
+			page.HeaderType &^= oggHeaderEOS | oggHeaderBOS
+			if index == 0 {
+				page.HeaderType |= oggHeaderBOS
+			}
+			pending = &page
+		}
+		if err := flush(true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggChecksum computes the page checksum with the checksum field read as zero.
+func oggChecksum(page []byte) uint32 {
+	var crc uint32
+	for i, b := range page {
+		if i >= 22 && i < 26 {
+			b = 0
+		}
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
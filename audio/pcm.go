@@ -0,0 +1,190 @@
+// Package audio converts raw audio between the formats used by the transcripts and tts packages.
+//
+// Samples are signed 16-bit linear PCM. Multi-channel audio is interleaved, and PCM byte streams are little-endian.
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+// ErrUnsupportedFormat is returned for formats that are not raw PCM or G.711.
+var ErrUnsupportedFormat = errors.New("unsupported audio format")
+
+// Encoding is the sample encoding of a byte stream.
+type Encoding int
+
+const (
+	// EncodingPCM16 is signed 16-bit little-endian linear PCM.
+	EncodingPCM16 Encoding = iota
+	// EncodingMulaw is G.711 μ-law, one byte per sample.
+	EncodingMulaw
+	// EncodingAlaw is G.711 A-law, one byte per sample.
+	EncodingAlaw
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingPCM16:
+		return "pcm16"
+	case EncodingMulaw:
+		return "ulaw"
+	case EncodingAlaw:
+		return "alaw"
+	default:
+		return "Encoding(" + strconv.Itoa(int(e)) + ")"
+	}
+}
+
+// BytesPerSample returns the size of one sample of one channel.
+func (e Encoding) BytesPerSample() int {
+	if e == EncodingPCM16 {
+		return 2
+	}
+	return 1
+}
+
+// Format describes a raw audio byte stream.
+type Format struct {
+	Encoding   Encoding
+	SampleRate int
+	// Channels defaults to 1 when zero.
+	Channels int
+}
+
+func (f Format) channels() int {
+	if f.Channels <= 0 {
+		return 1
+	}
+	return f.Channels
+}
+
+// FrameSize returns the size of one sample across all channels.
+func (f Format) FrameSize() int {
+	return f.Encoding.BytesPerSample() * f.channels()
+}
+
+func (f Format) validate() error {
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate %d", f.SampleRate)
+	}
+	switch f.Encoding {
+	case EncodingPCM16, EncodingMulaw, EncodingAlaw:
+		return nil
+	default:
+		return fmt.Errorf("invalid encoding %v", f.Encoding)
+	}
+}
+
+// FromTranscripts returns the mono format of a realtime transcription input format.
+func FromTranscripts(format transcripts.AudioFormat) (Format, error) {
+	return parseFormatName(string(format))
+}
+
+// FromTTS returns the mono format of a TTS output format.
+// MP3 and Opus output is compressed and returns ErrUnsupportedFormat.
+func FromTTS(format tts.AudioFormat) (Format, error) {
+	return parseFormatName(string(format))
+}
+
+func parseFormatName(name string) (Format, error) {
+	encoding, rate, ok := strings.Cut(name, "_")
+	if !ok {
+		return Format{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+	}
+	f := Format{Channels: 1}
+	switch encoding {
+	case "pcm":
+		f.Encoding = EncodingPCM16
+	case "ulaw":
+		f.Encoding = EncodingMulaw
+	case "alaw":
+		f.Encoding = EncodingAlaw
+	default:
+		return Format{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+	}
+	sampleRate, err := strconv.Atoi(rate)
+	if err != nil || sampleRate <= 0 {
+		return Format{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+	}
+	f.SampleRate = sampleRate
+	return f, nil
+}
+
+// DecodePCM16 decodes little-endian PCM bytes to samples. A trailing odd byte is ignored.
+func DecodePCM16(data []byte) []int16 {
+	out := make([]int16, len(data)/2)
+	for i := range out {
+		out[i] = int16(binary.LittleEndian.Uint16(data[2*i:]))
+	}
+	return out
+}
+
+// EncodePCM16 encodes samples as little-endian PCM bytes.
+func EncodePCM16(samples []int16) []byte {
+	out := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(s))
+	}
+	return out
+}
+
+// Decode decodes bytes in the given encoding to samples.
+func Decode(data []byte, encoding Encoding) []int16 {
+	switch encoding {
+	case EncodingMulaw:
+		return DecodeMulaw(data)
+	case EncodingAlaw:
+		return DecodeAlaw(data)
+	default:
+		return DecodePCM16(data)
+	}
+}
+
+// Encode encodes samples in the given encoding.
+func Encode(samples []int16, encoding Encoding) []byte {
+	switch encoding {
+	case EncodingMulaw:
+		return EncodeMulaw(samples)
+	case EncodingAlaw:
+		return EncodeAlaw(samples)
+	default:
+		return EncodePCM16(samples)
+	}
+}
+
+// DownmixToMono averages interleaved channels into one channel.
+func DownmixToMono(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return samples
+	}
+	out := make([]int16, len(samples)/channels)
+	for i := range out {
+		var sum int
+		for _, s := range samples[i*channels : (i+1)*channels] {
+			sum += int(s)
+		}
+		out[i] = int16(sum / channels)
+	}
+	return out
+}
+
+// UpmixFromMono copies one channel into the given number of interleaved channels.
+func UpmixFromMono(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return samples
+	}
+	out := make([]int16, len(samples)*channels)
+	for i, s := range samples {
+		for c := 0; c < channels; c++ {
+			out[i*channels+c] = s
+		}
+	}
+	return out
+}
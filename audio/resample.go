@@ -0,0 +1,233 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quality trades resampling accuracy for speed.
+type Quality int
+
+const (
+	// QualityLow interpolates linearly. It is the fastest but does not filter aliasing when downsampling.
+	QualityLow Quality = iota
+	// QualityMedium uses a short windowed-sinc filter. It is good enough for speech recognition.
+	QualityMedium
+	// QualityHigh uses a long windowed-sinc filter for playback.
+	QualityHigh
+)
+
+// zeroCrossings returns the number of sinc zero crossings on each side of the filter.
+func (q Quality) zeroCrossings() int {
+	switch q {
+	case QualityLow:
+		return 0
+	case QualityHigh:
+		return 32
+	default:
+		return 8
+	}
+}
+
+// maxFilterPhases bounds the precomputed filter table. Rate pairs with more phases compute taps on the fly.
+const maxFilterPhases = 1024
+
+// Resampler converts a stream of interleaved samples from one sample rate to another.
+// State is kept between calls, so a stream can be resampled chunk by chunk without clicks.
+// A Resampler is not safe for concurrent use.
+type Resampler struct {
+	from, to int
+	channels int
+	// half is the number of input frames the filter reaches on each side.
+	half   int
+	cutoff float64
+	kernel func(x float64) float64
+	table  [][]float64
+
+	history [][]float64
+	// base is the index of the first frame in history.
+	base     int64
+	next     int64
+	inFrames int64
+}
+
+// NewResampler creates a Resampler for interleaved audio with the given number of channels.
+// It panics if a rate is not positive.
+func NewResampler(fromRate, toRate, channels int, quality Quality) *Resampler {
+	if fromRate <= 0 || toRate <= 0 {
+		panic(fmt.Sprintf("audio: invalid resampling rates %d Hz to %d Hz", fromRate, toRate))
+	}
+	if channels <= 0 {
+		channels = 1
+	}
+	g := gcd(fromRate, toRate)
+	r := &Resampler{
+		from:     fromRate / g,
+		to:       toRate / g,
+		channels: channels,
+		history:  make([][]float64, channels),
+		cutoff:   math.Min(1, float64(toRate)/float64(fromRate)),
+	}
+	if crossings := quality.zeroCrossings(); crossings == 0 {
+		r.half = 1
+		r.kernel = func(x float64) float64 { return math.Max(0, 1-math.Abs(x)) }
+	} else {
+		r.half = int(math.Ceil(float64(crossings) / r.cutoff))
+		width := float64(r.half)
+		r.kernel = func(x float64) float64 {
+			if math.Abs(x) >= width {
+				return 0
+			}
+			return r.cutoff * sinc(r.cutoff*x) * blackman(x/width)
+		}
+	}
+	if r.to <= maxFilterPhases {
+		r.table = make([][]float64, r.to)
+		for phase := range r.table {
+			r.table[phase] = r.taps(phase)
+		}
+	}
+	return r
+}
+
+// Resample converts a complete buffer of interleaved samples. Like NewResampler, it panics if a
+// rate is not positive.
+func Resample(samples []int16, fromRate, toRate, channels int, quality Quality) []int16 {
+	if fromRate == toRate && fromRate > 0 {
+		return samples
+	}
+	r := NewResampler(fromRate, toRate, channels, quality)
+	return append(r.Process(samples), r.Flush()...)
+}
+
+// Process resamples the next chunk. The chunk must hold whole frames.
+// The output lags the input by the filter length; Flush returns the rest.
+func (r *Resampler) Process(samples []int16) []int16 {
+	if r.from == r.to {
+		return samples
+	}
+	frames := len(samples) / r.channels
+	for c := range r.history {
+		for i := 0; i < frames; i++ {
+			r.history[c] = append(r.history[c], float64(samples[i*r.channels+c]))
+		}
+	}
+	r.inFrames += int64(frames)
+	return r.drain(r.base + int64(len(r.history[0])))
+}
+
+// Flush returns the remaining output and resets the Resampler for a new stream.
+func (r *Resampler) Flush() []int16 {
+	if r.from == r.to {
+		return nil
+	}
+	want := (r.inFrames*int64(r.to) + int64(r.from) - 1) / int64(r.from)
+	out := r.drainUntil(r.base+int64(len(r.history[0]))+int64(r.half), want)
+	r.base, r.next, r.inFrames = 0, 0, 0
+	for c := range r.history {
+		r.history[c] = r.history[c][:0]
+	}
+	return out
+}
+
+func (r *Resampler) drain(available int64) []int16 {
+	return r.drainUntil(available, math.MaxInt64)
+}
+
+// drainUntil produces output frames whose filter ends before available, up to limit frames in total.
+// Frames past the end of history read as silence.
+func (r *Resampler) drainUntil(available, limit int64) []int16 {
+	var out []int16
+	for r.next < limit {
+		num := r.next * int64(r.from)
+		center := num / int64(r.to)
+		if center+int64(r.half) >= available {
+			break
+		}
+		phase := int(num % int64(r.to))
+		taps := r.phaseTaps(phase)
+		first := center - int64(r.half) + 1
+		for c := 0; c < r.channels; c++ {
+			history := r.history[c]
+			var acc float64
+			for j, tap := range taps {
+				if k := first + int64(j) - r.base; k >= 0 && k < int64(len(history)) {
+					acc += tap * history[k]
+				}
+			}
+			out = append(out, clampSample(acc))
+		}
+		r.next++
+	}
+
+	// Drop frames no later output needs.
+	keep := r.next*int64(r.from)/int64(r.to) - int64(r.half) + 1
+	if drop := keep - r.base; drop > 0 {
+		drop = min(drop, int64(len(r.history[0])))
+		for c := range r.history {
+			r.history[c] = append(r.history[c][:0], r.history[c][drop:]...)
+		}
+		r.base += drop
+	}
+	return out
+}
+
+func (r *Resampler) phaseTaps(phase int) []float64 {
+	if r.table != nil {
+		return r.table[phase]
+	}
+	return r.taps(phase)
+}
+
+// taps returns normalized filter coefficients for the input frames center-half+1 .. center+half.
+func (r *Resampler) taps(phase int) []float64 {
+	frac := float64(phase) / float64(r.to)
+	taps := make([]float64, 2*r.half)
+	var sum float64
+	for j := range taps {
+		taps[j] = r.kernel(float64(j-r.half+1) - frac)
+		sum += taps[j]
+	}
+	if sum != 0 {
+		for j := range taps {
+			taps[j] /= sum
+		}
+	}
+	return taps
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// blackman is the Blackman window over x in [-1, 1].
+func blackman(x float64) float64 {
+	t := math.Pi * (x + 1)
+	return 0.42 - 0.5*math.Cos(t) + 0.08*math.Cos(2*t)
+}
+
+func clampSample(v float64) int16 {
+	v = math.Round(v)
+	switch {
+	case v > math.MaxInt16:
+		return math.MaxInt16
+	case v < math.MinInt16:
+		return math.MinInt16
+	default:
+		return int16(v)
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a == 0 {
+		return 1
+	}
+	return a
+}
@@ -0,0 +1,303 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+var (
+	// ErrInvalidWAV is returned when a stream is not a supported WAV file.
+	ErrInvalidWAV = errors.New("invalid WAV data")
+	// ErrFormatMismatch is returned when a WAV file does not have the expected format.
+	ErrFormatMismatch = errors.New("audio format mismatch")
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatAlaw       = 6
+	wavFormatMulaw      = 7
+	wavFormatExtensible = 0xfffe
+	wavHeaderSize       = 44
+	// wavUnknownSize is written as the chunk sizes of a stream whose length is not known up front.
+	wavUnknownSize = math.MaxUint32
+	// wavMaxFormatSize bounds the fmt chunk, which is 16 to 40 bytes in practice, before it is read.
+	wavMaxFormatSize = 1024
+)
+
+// WAVWriter writes audio into a RIFF WAV container.
+//
+// When the destination is an io.WriteSeeker, such as an *os.File, Close rewrites the header with the
+// final sizes. Otherwise the header carries the 0xFFFFFFFF "unknown length" sizes used for streaming,
+// which WAVReader and most players accept. Close does not close the destination.
+type WAVWriter struct {
+	w      io.Writer
+	format Format
+	size   int64
+	err    error
+	closed bool
+}
+
+// NewWAVWriter writes a WAV header for format to w and returns a writer for the sample data.
+func NewWAVWriter(w io.Writer, format Format) (*WAVWriter, error) {
+	if err := format.validate(); err != nil {
+		return nil, err
+	}
+	ww := &WAVWriter{w: w, format: format}
+	if _, err := w.Write(ww.header(wavUnknownSize)); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+// Write appends sample data.
+func (w *WAVWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := w.w.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// HandleStreamEvent is a tts.StreamEventHandler that writes the audio of every tts.AudioEvent.
+// Write errors are reported by Close.
+func (w *WAVWriter) HandleStreamEvent(_ context.Context, event tts.StreamEvent) {
+	if e, ok := event.(tts.AudioEvent); ok && len(e.Audio) > 0 {
+		_, _ = w.Write(e.Audio)
+	}
+}
+
+// Close pads the data chunk to an even length and, when w can seek, fixes the sizes in the header.
+func (w *WAVWriter) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	if w.size%2 == 1 {
+		if _, err := w.w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	seeker, ok := w.w.(io.WriteSeeker)
+	if !ok {
+		return nil
+	}
+	// Pipes, FIFOs and terminals are files too but cannot seek. They keep the streaming header.
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+	if w.size > math.MaxUint32-wavHeaderSize {
+		_, _ = seeker.Seek(0, io.SeekEnd)
+		return fmt.Errorf("WAV data of %d bytes exceeds the RIFF size limit", w.size)
+	}
+	if _, err := seeker.Write(w.header(uint32(w.size))); err != nil {
+		return err
+	}
+	_, err := seeker.Seek(0, io.SeekEnd)
+	return err
+}
+
+func (w *WAVWriter) header(dataSize uint32) []byte {
+	tag := uint16(wavFormatPCM)
+	switch w.format.Encoding {
+	case EncodingAlaw:
+		tag = wavFormatAlaw
+	case EncodingMulaw:
+		tag = wavFormatMulaw
+	}
+	channels := w.format.channels()
+	bytesPerSample := w.format.Encoding.BytesPerSample()
+	riffSize := uint32(wavUnknownSize)
+	if dataSize != wavUnknownSize {
+		riffSize = wavHeaderSize - 8 + dataSize + dataSize%2
+	}
+
+	h := make([]byte, wavHeaderSize)
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], riffSize)
+	copy(h[8:], "WAVE")
+	copy(h[12:], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], tag)
+	binary.LittleEndian.PutUint16(h[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(h[24:], uint32(w.format.SampleRate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(w.format.SampleRate*channels*bytesPerSample))
+	binary.LittleEndian.PutUint16(h[32:], uint16(channels*bytesPerSample))
+	binary.LittleEndian.PutUint16(h[34:], uint16(8*bytesPerSample))
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], dataSize)
+	return h
+}
+
+// WriteWAV copies raw audio from r, such as tts.StreamResponse.Audio, into a WAV file written to w.
+func WriteWAV(w io.Writer, format Format, r io.Reader) (int64, error) {
+	ww, err := NewWAVWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(ww, r)
+	if closeErr := ww.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// WAVReader reads the sample data of a WAV file.
+type WAVReader struct {
+	r      io.Reader
+	format Format
+	// remaining is the number of unread data bytes, or -1 when the size is unknown.
+	remaining int64
+}
+
+// NewWAVReader parses the header of a WAV file and positions r at the start of the sample data.
+// PCM16, μ-law and A-law files are supported.
+func NewWAVReader(r io.Reader) (*WAVReader, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWAV, err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: missing RIFF/WAVE header", ErrInvalidWAV)
+	}
+
+	var (
+		format    Format
+		hasFormat bool
+	)
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("%w: no data chunk: %v", ErrInvalidWAV, err)
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:])
+		switch id {
+		case "fmt ":
+			if size > wavMaxFormatSize {
+				return nil, fmt.Errorf("%w: fmt chunk of %d bytes", ErrInvalidWAV, size)
+			}
+			body := make([]byte, size)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidWAV, err)
+			}
+			parsed, err := parseWAVFormat(body)
+			if err != nil {
+				return nil, err
+			}
+			format, hasFormat = parsed, true
+			if err := skipWAVPadding(r, int64(size)); err != nil {
+				return nil, err
+			}
+		case "data":
+			if !hasFormat {
+				return nil, fmt.Errorf("%w: data chunk before fmt chunk", ErrInvalidWAV)
+			}
+			remaining := int64(size)
+			if size == wavUnknownSize {
+				remaining = -1
+			}
+			return &WAVReader{r: r, format: format, remaining: remaining}, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidWAV, err)
+			}
+			if err := skipWAVPadding(r, int64(size)); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func parseWAVFormat(body []byte) (Format, error) {
+	if len(body) < 16 {
+		return Format{}, fmt.Errorf("%w: short fmt chunk", ErrInvalidWAV)
+	}
+	tag := binary.LittleEndian.Uint16(body[0:])
+	if tag == wavFormatExtensible && len(body) >= 26 {
+		// The first two bytes of the sub-format GUID hold the format tag.
+		tag = binary.LittleEndian.Uint16(body[24:])
+	}
+	bits := binary.LittleEndian.Uint16(body[14:])
+	format := Format{
+		Channels:   int(binary.LittleEndian.Uint16(body[2:])),
+		SampleRate: int(binary.LittleEndian.Uint32(body[4:])),
+	}
+	switch {
+	case tag == wavFormatPCM && bits == 16:
+		format.Encoding = EncodingPCM16
+	case tag == wavFormatMulaw && bits == 8:
+		format.Encoding = EncodingMulaw
+	case tag == wavFormatAlaw && bits == 8:
+		format.Encoding = EncodingAlaw
+	default:
+		return Format{}, fmt.Errorf("%w: format tag %d with %d bits per sample", ErrUnsupportedFormat, tag, bits)
+	}
+	if format.Channels <= 0 || format.SampleRate <= 0 {
+		return Format{}, fmt.Errorf("%w: %d channels at %d Hz", ErrInvalidWAV, format.Channels, format.SampleRate)
+	}
+	return format, nil
+}
+
+func skipWAVPadding(r io.Reader, size int64) error {
+	if size%2 == 0 {
+		return nil
+	}
+	if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWAV, err)
+	}
+	return nil
+}
+
+// Format returns the format of the sample data.
+func (r *WAVReader) Format() Format {
+	return r.format
+}
+
+// Read reads sample data. It returns io.EOF at the end of the data chunk.
+func (r *WAVReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	if r.remaining > 0 && int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	if r.remaining > 0 {
+		r.remaining -= int64(n)
+		if errors.Is(err, io.EOF) && r.remaining > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+	return n, err
+}
+
+// CheckFormat returns ErrFormatMismatch unless the file is mono audio in the given realtime input format.
+// Use a Converter or Writer to feed files in other formats to a Recognizer.
+func (r *WAVReader) CheckFormat(format transcripts.AudioFormat) error {
+	want, err := FromTranscripts(format)
+	if err != nil {
+		return err
+	}
+	if r.format.Encoding != want.Encoding || r.format.SampleRate != want.SampleRate || r.format.channels() != 1 {
+		return fmt.Errorf("%w: file is %v at %d Hz with %d channels, want %s",
+			ErrFormatMismatch, r.format.Encoding, r.format.SampleRate, r.format.channels(), format)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+func TestWAVWriterFixesHeaderOnSeekableFile(t *testing.T) {
+	t.Parallel()
+
+	pcm := EncodePCM16(sine(440, 16000, 1600, 5000))
+	path := filepath.Join(t.TempDir(), "out.wav")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWAVWriter(file, Format{Encoding: EncodingPCM16, SampleRate: 16000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.HandleStreamEvent(context.Background(), tts.AudioEvent{Audio: pcm[:1000]})
+	w.HandleStreamEvent(context.Background(), tts.AudioEvent{Audio: pcm[1000:], IsFinal: true})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := binary.LittleEndian.Uint32(data[4:]), uint32(36+len(pcm)); got != want {
+		t.Errorf("RIFF size = %d, want %d", got, want)
+	}
+	if got, want := binary.LittleEndian.Uint32(data[40:]), uint32(len(pcm)); got != want {
+		t.Errorf("data size = %d, want %d", got, want)
+	}
+
+	r, err := NewWAVReader(bytes.NewReader(append(data, "trailing"...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CheckFormat(transcripts.AudioFormatPcm_16000); err != nil {
+		t.Errorf("CheckFormat() error = %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, pcm) {
+		t.Errorf("read %d bytes, want the %d written", len(got), len(pcm))
+	}
+}
+
+func TestWAVStreamingSizes(t *testing.T) {
+	t.Parallel()
+
+	ulaw := EncodeMulaw(sine(440, 8000, 801, 5000))
+	var buf bytes.Buffer
+	if _, err := WriteWAV(&buf, Format{Encoding: EncodingMulaw, SampleRate: 8000}, bytes.NewReader(ulaw)); err != nil {
+		t.Fatal(err)
+	}
+	if got := binary.LittleEndian.Uint32(buf.Bytes()[40:]); got != wavUnknownSize {
+		t.Errorf("data size = %#x, want unknown size", got)
+	}
+
+	r, err := NewWAVReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Format(), (Format{Encoding: EncodingMulaw, SampleRate: 8000, Channels: 1}); got != want {
+		t.Errorf("Format() = %+v, want %+v", got, want)
+	}
+	if err := r.CheckFormat(transcripts.AudioFormatPcm_16000); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("CheckFormat() error = %v, want %v", err, ErrFormatMismatch)
+	}
+	// With an unknown size, the pad byte after odd-length data reads as data.
+	got, _ := io.ReadAll(r)
+	if want := append(ulaw, 0); !bytes.Equal(got, want) {
+		t.Errorf("read %d bytes, want %d", len(got), len(want))
+	}
+}
+
+func TestWAVWriterKeepsStreamingHeaderOnPipe(t *testing.T) {
+	t.Parallel()
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	w, err := NewWAVWriter(pw, Format{Encoding: EncodingMulaw, SampleRate: 8000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Errorf("Close() error = %v, want nil on a pipe", err)
+	}
+	if err = pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := binary.LittleEndian.Uint32(data[40:]); got != wavUnknownSize {
+		t.Errorf("data size = %#x, want unknown size", got)
+	}
+	if got, want := data[wavHeaderSize:], []byte{1, 2, 3, 0}; !bytes.Equal(got, want) {
+		t.Errorf("data = %v, want %v with the pad byte", got, want)
+	}
+}
+
+func TestNewWAVReaderRejectsInvalidData(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewWAVReader(bytes.NewReader([]byte("ID3 not a wav file"))); !errors.Is(err, ErrInvalidWAV) {
+		t.Errorf("NewWAVReader() error = %v, want %v", err, ErrInvalidWAV)
+	}
+
+	huge := []byte("RIFF\xff\xff\xff\xffWAVEfmt \xff\xff\xff\x7f")
+	if _, err := NewWAVReader(bytes.NewReader(huge)); !errors.Is(err, ErrInvalidWAV) {
+		t.Errorf("NewWAVReader(huge fmt chunk) error = %v, want %v", err, ErrInvalidWAV)
+	}
+}
+
+func TestWAVReaderEmptyDataChunk(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w, err := NewWAVWriter(&buf, Format{Encoding: EncodingPCM16, SampleRate: 16000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	data := bytes.Clone(buf.Bytes()[:wavHeaderSize])
+	binary.LittleEndian.PutUint32(data[40:], 0)
+	data = append(data, "LIST\x04\x00\x00\x00INFO"...)
+
+	r, err := NewWAVReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(r); len(got) != 0 {
+		t.Errorf("read %q from an empty data chunk, want nothing", got)
+	}
+}
@@ -0,0 +1,561 @@
+package elevenlabstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/coder/websocket"
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
+
+// ErrNoInteraction is returned in replay mode when a request or dial has no matching recording.
+var ErrNoInteraction = errors.New("elevenlabstest: no recorded interaction matches")
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// ModeReplay serves every request and websocket session from the cassette file and never
+	// touches the network.
+	ModeReplay Mode = iota
+	// ModeRecord passes traffic to the real API and records it. Save writes the cassette.
+	ModeRecord
+	// ModeRecordOnce replays when the cassette file exists and records otherwise.
+	ModeRecordOnce
+)
+
+// Cassette is the recorded traffic of a test, stored as JSON.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded HTTP exchange or websocket session.
+type Interaction struct {
+	// Kind is "http" or "websocket".
+	Kind     string           `json:"kind"`
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+	// Frames are the websocket messages in the order they were sent and received.
+	Frames []Frame `json:"frames,omitempty"`
+	// Close is how the websocket session ended, if the client saw it end. Without it, replayed
+	// reads block at the end of the frames until the client closes the connection.
+	Close *RecordedClose `json:"close,omitempty"`
+	// DialError is the error of a websocket dial that failed, such as a rejected handshake.
+	// Replaying the interaction fails the dial with the same message.
+	DialError string `json:"dial_error,omitempty"`
+}
+
+// RecordedRequest is a recorded HTTP request or websocket handshake.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Payload     `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded HTTP response or websocket handshake response.
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Payload     `json:"body,omitempty"`
+}
+
+// Frame is one websocket message.
+type Frame struct {
+	// Direction is "send" for client messages and "recv" for server messages.
+	Direction string  `json:"direction"`
+	Binary    bool    `json:"binary,omitempty"`
+	Data      Payload `json:"data"`
+}
+
+// RecordedClose is the end of a websocket session. Status is -1 when the connection failed
+// without a close frame.
+type RecordedClose struct {
+	Status int    `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Payload is message or body data. It is stored as a string when it is valid UTF-8 and as
+// {"base64": "..."} otherwise, so JSON stays readable and audio stays exact.
+type Payload []byte
+
+// MarshalJSON implements json.Marshaler.
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(p) {
+		return json.Marshal(string(p))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(p)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Payload) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*p = Payload(text)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	*p = decoded
+	return err
+}
+
+// Recorder records HTTP and websocket traffic to a cassette file and replays it offline.
+// Plug Transport into ClientConfig.HTTPMiddleware and Dialer into WithDialer.
+//
+// Replay is sequential: the n-th HTTP request gets the n-th recorded HTTP exchange and the n-th
+// dial the n-th recorded websocket session, as long as method and path match. Server messages
+// are replayed once the client has sent the messages that preceded them in the recording.
+type Recorder struct {
+	path   string
+	record bool
+	scrub  func(*Interaction)
+
+	mu       sync.Mutex
+	cassette Cassette
+	// sessions are the websocket sessions being recorded, by index in cassette.Interactions.
+	sessions map[int]*Interaction
+	nextHTTP int
+	nextWS   int
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithScrubber sets a function that edits every interaction before it is saved, in addition to
+// the default scrubbing of API keys and tokens.
+func WithScrubber(scrub func(*Interaction)) RecorderOption {
+	return func(r *Recorder) {
+		r.scrub = scrub
+	}
+}
+
+// NewRecorder creates a Recorder for the cassette at path. In replay mode the file must exist.
+func NewRecorder(path string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{path: path, record: mode == ModeRecord}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if mode == ModeRecordOnce {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			r.record = true
+		}
+	}
+	if r.record {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	if err = json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Recording reports whether the recorder passes traffic to the network.
+func (r *Recorder) Recording() bool {
+	return r.record
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing in replay mode.
+// Close websocket sessions first; sessions still open are saved as far as they got.
+func (r *Recorder) Save() error {
+	if !r.record {
+		return nil
+	}
+	r.mu.Lock()
+	cassette := Cassette{Interactions: slices.Clone(r.cassette.Interactions)}
+	for index, session := range r.sessions {
+		snapshot := *session
+		snapshot.Frames = slices.Clone(session.Frames)
+		if session.Close != nil {
+			end := *session.Close
+			snapshot.Close = &end
+		}
+		if r.scrub != nil {
+			r.scrub(&snapshot)
+		}
+		cassette.Interactions[index] = snapshot
+	}
+	r.mu.Unlock()
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// Transport returns an http.RoundTripper that records calls made through base, or replays them.
+// A nil base means http.DefaultTransport. It has the signature of transcripts.HTTPMiddleware.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transcripts.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if r.record {
+			return r.recordHTTP(base, req)
+		}
+		return r.replayHTTP(req)
+	})
+}
+
+func (r *Recorder) recordHTTP(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.add(Interaction{
+		Kind:     "http",
+		Request:  RecordedRequest{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: reqBody},
+		Response: RecordedResponse{Status: resp.StatusCode, Header: resp.Header.Clone(), Body: respBody},
+	})
+	return resp, nil
+}
+
+func (r *Recorder) replayHTTP(req *http.Request) (*http.Response, error) {
+	interaction, err := r.next("http", req.Method, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+		StatusCode:    interaction.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Dialer returns a transcripts.WebSocketDialer that records sessions opened through base, or
+// replays them. A nil base means transcripts.DefaultDialer().
+func (r *Recorder) Dialer(base transcripts.WebSocketDialer) transcripts.WebSocketDialer {
+	if base == nil {
+		base = transcripts.DefaultDialer()
+	}
+	return &recorderDialer{recorder: r, base: base}
+}
+
+type recorderDialer struct {
+	recorder *Recorder
+	base     transcripts.WebSocketDialer
+}
+
+func (d *recorderDialer) Dial(ctx context.Context, rawURL string, header http.Header) (transcripts.WebSocketConn, error) {
+	r := d.recorder
+	if !r.record {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		interaction, err := r.next("websocket", http.MethodGet, u)
+		if err != nil {
+			return nil, err
+		}
+		if interaction.DialError != "" {
+			return nil, errors.New(interaction.DialError)
+		}
+		return newReplayConn(interaction), nil
+	}
+
+	conn, err := d.base.Dial(ctx, rawURL, header)
+	interaction := Interaction{
+		Kind:    "websocket",
+		Request: RecordedRequest{Method: http.MethodGet, URL: rawURL, Header: header.Clone()},
+	}
+	if err != nil {
+		redacted := interaction.Request.URL
+		if u, parseErr := url.Parse(rawURL); parseErr == nil {
+			redacted = transcripts.RedactURL(u)
+		}
+		interaction.DialError = strings.ReplaceAll(err.Error(), rawURL, redacted)
+		r.add(interaction)
+		return nil, err
+	}
+	if resp := conn.Response(); resp != nil {
+		interaction.Response = RecordedResponse{Status: resp.StatusCode, Header: resp.Header.Clone()}
+	}
+	interaction = scrubDefaults(interaction)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sessions == nil {
+		r.sessions = make(map[int]*Interaction)
+	}
+	r.sessions[len(r.cassette.Interactions)] = &interaction
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{})
+	return &recordingConn{WebSocketConn: conn, recorder: r, interaction: &interaction}, nil
+}
+
+// recordingConn records the frames of a live session. Frames are scrubbed as they are added;
+// Save copies the session into the cassette.
+type recordingConn struct {
+	transcripts.WebSocketConn
+	recorder    *Recorder
+	interaction *Interaction
+}
+
+func (c *recordingConn) ReadMessage(ctx context.Context) (transcripts.MessageType, []byte, error) {
+	messageType, data, err := c.WebSocketConn.ReadMessage(ctx)
+	c.recorder.mu.Lock()
+	defer c.recorder.mu.Unlock()
+	if err != nil {
+		var permanent *transcripts.PermanentError
+		if errors.As(err, &permanent) && c.interaction.Close == nil {
+			var closeErr websocket.CloseError
+			if errors.As(err, &closeErr) {
+				c.interaction.Close = &RecordedClose{Status: int(closeErr.Code), Reason: closeErr.Reason}
+			} else if ctx.Err() == nil {
+				c.interaction.Close = &RecordedClose{Status: -1, Reason: permanent.Err.Error()}
+			}
+		}
+		return messageType, data, err
+	}
+	c.addLocked("recv", messageType, data)
+	return messageType, data, nil
+}
+
+func (c *recordingConn) WriteMessage(ctx context.Context, messageType transcripts.MessageType, data []byte) error {
+	err := c.WebSocketConn.WriteMessage(ctx, messageType, data)
+	if err == nil {
+		c.recorder.mu.Lock()
+		c.addLocked("send", messageType, append([]byte(nil), data...))
+		c.recorder.mu.Unlock()
+	}
+	return err
+}
+
+// addLocked appends a scrubbed frame to the session.
+func (c *recordingConn) addLocked(direction string, messageType transcripts.MessageType, data []byte) {
+	frame := Frame{Direction: direction, Binary: messageType == transcripts.MessageBinary, Data: data}
+	if !frame.Binary {
+		frame.Data = scrubPayload(frame.Data)
+	}
+	c.interaction.Frames = append(c.interaction.Frames, frame)
+}
+
+// replayConn plays back a recorded session.
+type replayConn struct {
+	interaction Interaction
+
+	mu      sync.Mutex
+	changed chan struct{}
+	pos     int // next frame
+	matched int // recorded client frames stepped over
+	sent    int // client messages written
+	closed  bool
+}
+
+func newReplayConn(interaction Interaction) *replayConn {
+	return &replayConn{interaction: interaction, changed: make(chan struct{})}
+}
+
+func (c *replayConn) ReadMessage(ctx context.Context) (transcripts.MessageType, []byte, error) {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return 0, nil, transcripts.Permanent(net.ErrClosed)
+		}
+		// Step over recorded client frames the client has sent; wait for the others.
+		for c.pos < len(c.interaction.Frames) && c.interaction.Frames[c.pos].Direction == "send" && c.matched < c.sent {
+			c.pos++
+			c.matched++
+		}
+		if c.pos >= len(c.interaction.Frames) && c.interaction.Close != nil {
+			c.mu.Unlock()
+			return 0, nil, c.endError()
+		}
+		if c.pos < len(c.interaction.Frames) && c.interaction.Frames[c.pos].Direction == "recv" {
+			frame := c.interaction.Frames[c.pos]
+			c.pos++
+			c.mu.Unlock()
+			messageType := transcripts.MessageText
+			if frame.Binary {
+				messageType = transcripts.MessageBinary
+			}
+			return messageType, frame.Data, nil
+		}
+		// Wait for the next client message, or for Close when the recording ends without a close
+		// because the client stopped reading there.
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, nil, transcripts.Permanent(ctx.Err())
+		}
+	}
+}
+
+func (c *replayConn) endError() error {
+	end := c.interaction.Close
+	if end.Status < 0 {
+		return transcripts.Permanent(errors.New(end.Reason))
+	}
+	return transcripts.Permanent(websocket.CloseError{Code: websocket.StatusCode(end.Status), Reason: end.Reason})
+}
+
+func (c *replayConn) WriteMessage(context.Context, transcripts.MessageType, []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return transcripts.Permanent(net.ErrClosed)
+	}
+	c.sent++
+	c.notifyLocked()
+	return nil
+}
+
+func (c *replayConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		c.notifyLocked()
+	}
+	return nil
+}
+
+func (c *replayConn) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *replayConn) Response() *http.Response {
+	return &http.Response{StatusCode: c.interaction.Response.Status, Header: c.interaction.Response.Header.Clone()}
+}
+
+func (c *replayConn) Ping(context.Context) error {
+	return nil
+}
+
+func (r *Recorder) add(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, r.scrubbed(interaction))
+}
+
+// next returns the next recorded interaction of kind and checks that it matches method and u.
+func (r *Recorder) next(kind, method string, u *url.URL) (Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counter := &r.nextHTTP
+	if kind == "websocket" {
+		counter = &r.nextWS
+	}
+	seen := 0
+	for _, interaction := range r.cassette.Interactions {
+		if interaction.Kind != kind {
+			continue
+		}
+		if seen < *counter {
+			seen++
+			continue
+		}
+		recorded, err := url.Parse(interaction.Request.URL)
+		if err != nil {
+			return Interaction{}, err
+		}
+		if interaction.Request.Method != method || recorded.Path != u.Path {
+			return Interaction{}, fmt.Errorf("%w: %s %s %s, recorded %s %s", ErrNoInteraction,
+				kind, method, u.Path, interaction.Request.Method, recorded.Path)
+		}
+		*counter++
+		return interaction, nil
+	}
+	return Interaction{}, fmt.Errorf("%w: %s %s %s, cassette %s has no more %s interactions", ErrNoInteraction,
+		kind, method, u.Path, r.path, kind)
+}
+
+// sensitiveHeaders are replaced in recorded requests and responses.
+var sensitiveHeaders = []string{"xi-api-key", "Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveField matches API keys and tokens inside JSON bodies and messages.
+var sensitiveField = regexp.MustCompile(`("(?:xi_api_key|xi-api-key|api_key|token|authorization)"\s*:\s*)"[^"]*"`)
+
+func (r *Recorder) scrubbed(interaction Interaction) Interaction {
+	interaction = scrubDefaults(interaction)
+	if r.scrub != nil {
+		r.scrub(&interaction)
+	}
+	return interaction
+}
+
+// scrubDefaults removes API keys and tokens from headers, the URL, the body and the frames.
+func scrubDefaults(interaction Interaction) Interaction {
+	interaction.Request.Header = scrubHeader(interaction.Request.Header)
+	interaction.Response.Header = scrubHeader(interaction.Response.Header)
+	if u, err := url.Parse(interaction.Request.URL); err == nil {
+		interaction.Request.URL = transcripts.RedactURL(u)
+	}
+	interaction.Request.Body = scrubPayload(interaction.Request.Body)
+	interaction.Frames = slices.Clone(interaction.Frames)
+	for i, frame := range interaction.Frames {
+		if !frame.Binary {
+			interaction.Frames[i].Data = scrubPayload(frame.Data)
+		}
+	}
+	return interaction
+}
+
+func scrubHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range sensitiveHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, "REDACTED")
+		}
+	}
+	return header
+}
+
+func scrubPayload(p Payload) Payload {
+	if !utf8.Valid(p) || !strings.Contains(string(p), `"`) {
+		return p
+	}
+	return Payload(sensitiveField.ReplaceAll(p, []byte(`$1"REDACTED"`)))
+}
@@ -0,0 +1,205 @@
+package elevenlabstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+func TestRecorderReplaysHTTPWithoutTheNetwork(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "http.json")
+	server := NewServer(WithAudio([]byte("fake-audio")))
+	cfg := server.TTSConfig("secret-key")
+	baseURL := cfg.BaseURL
+
+	recorder, err := NewRecorder(path, ModeRecordOnce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recorder.Recording() {
+		t.Fatal("ModeRecordOnce without a cassette does not record")
+	}
+	cfg.HTTPMiddleware = []transcripts.HTTPMiddleware{recorder.Transport}
+	recorded, err := tts.NewClientWithConfig(cfg).Synthesize(context.Background(), tts.SynthesisRequest{VoiceID: "voice_123", Text: "Hi."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-key") {
+		t.Errorf("cassette contains the API key:\n%s", data)
+	}
+
+	replayer, err := NewRecorder(path, ModeRecordOnce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayer.Recording() {
+		t.Fatal("ModeRecordOnce with a cassette records")
+	}
+	cfg = tts.DefaultConfig("other-key")
+	cfg.BaseURL = baseURL
+	cfg.HTTPMiddleware = []transcripts.HTTPMiddleware{replayer.Transport}
+	client := tts.NewClientWithConfig(cfg)
+	replayed, err := client.Synthesize(context.Background(), tts.SynthesisRequest{VoiceID: "voice_123", Text: "Hi."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(replayed.Audio), "fake-audio"; got != want {
+		t.Errorf("audio = %q, want %q", got, want)
+	}
+	if got, want := replayed.RequestID, recorded.RequestID; got != want {
+		t.Errorf("request id = %q, want %q", got, want)
+	}
+	if _, err = client.Synthesize(context.Background(), tts.SynthesisRequest{VoiceID: "voice_123", Text: "Hi."}); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("extra request error = %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestRecorderReplaysRealtimeSessions(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "realtime.json")
+	server := NewServer(WithAudio([]byte("abc")))
+	cfg := server.TTSConfig("secret-key")
+
+	synthesize := func(dialer transcripts.WebSocketDialer) string {
+		t.Helper()
+		conn, err := tts.NewClientWithConfig(cfg).ConnectRealtime(context.Background(),
+			tts.StreamInputRequest{VoiceID: "voice_123"}, tts.WithDialer(dialer))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var audio bytes.Buffer
+		streamer := tts.NewRealtimeSynthesizer(context.Background(), conn, func(_ context.Context, event tts.StreamEvent) {
+			if e, ok := event.(tts.AudioEvent); ok {
+				audio.Write(e.Audio)
+			}
+		})
+		streamer.Start()
+		if err = streamer.SendText("Hello there. "); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err = streamer.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return audio.String()
+	}
+
+	recorder, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := synthesize(recorder.Dialer(nil)), "abc"; got != want {
+		t.Fatalf("recorded audio = %q, want %q", got, want)
+	}
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-key") {
+		t.Errorf("cassette contains the API key:\n%s", data)
+	}
+
+	replayer, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := synthesize(replayer.Dialer(nil)), "abc"; got != want {
+		t.Errorf("replayed audio = %q, want %q", got, want)
+	}
+}
+
+func TestReplayConnWaitsForClientMessages(t *testing.T) {
+	t.Parallel()
+
+	conn := newReplayConn(Interaction{
+		Kind: "websocket",
+		Frames: []Frame{
+			{Direction: "recv", Data: Payload(`{"message_type":"session_started"}`)},
+			{Direction: "send", Data: Payload(`{"message_type":"input_audio_chunk"}`)},
+			{Direction: "recv", Data: Payload(`{"message_type":"partial_transcript"}`)},
+		},
+		Close: &RecordedClose{Status: 1000},
+	})
+	ctx := context.Background()
+	if _, data, err := conn.ReadMessage(ctx); err != nil || !strings.Contains(string(data), "session_started") {
+		t.Fatalf("first read = %s, %v", data, err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := conn.ReadMessage(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("read before the client message = %v, want a deadline error", err)
+	}
+
+	if err := conn.WriteMessage(ctx, transcripts.MessageText, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, data, err := conn.ReadMessage(ctx); err != nil || !strings.Contains(string(data), "partial_transcript") {
+		t.Fatalf("read after the client message = %s, %v", data, err)
+	}
+	var permanent *transcripts.PermanentError
+	if _, _, err := conn.ReadMessage(ctx); !errors.As(err, &permanent) {
+		t.Errorf("read at the end = %v, want a permanent close error", err)
+	}
+}
+
+func TestRecorderReplaysFailedDials(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rejected.json")
+	server := NewServer(WithAPIKey("test-key"))
+	cfg := server.TTSConfig("wrong-key")
+	connect := func(dialer transcripts.WebSocketDialer) error {
+		_, err := tts.NewClientWithConfig(cfg).ConnectRealtime(context.Background(),
+			tts.StreamInputRequest{VoiceID: "voice_123"}, tts.WithDialer(dialer))
+		return err
+	}
+
+	recorder, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := connect(recorder.Dialer(nil))
+	if recorded == nil {
+		t.Fatal("dial with a wrong key succeeded")
+	}
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	replayer, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := connect(replayer.Dialer(nil))
+	if errors.Is(replayed, ErrNoInteraction) || replayed == nil || replayed.Error() != recorded.Error() {
+		t.Errorf("replayed error = %v, want %v", replayed, recorded)
+	}
+}
@@ -0,0 +1,107 @@
+package elevenlabstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
+
+// streamChunkSize is the size of the chunks RouteTTSStream writes.
+const streamChunkSize = 1024
+
+func (s *Server) serveTTS(w http.ResponseWriter, r *http.Request, req *Request) {
+	text, ok := synthesisText(w, req)
+	if !ok {
+		return
+	}
+	setAudioHeaders(w, r, text)
+	_, _ = w.Write(s.audio)
+}
+
+func (s *Server) serveTTSStream(w http.ResponseWriter, r *http.Request, req *Request) {
+	text, ok := synthesisText(w, req)
+	if !ok {
+		return
+	}
+	setAudioHeaders(w, r, text)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	for audio := s.audio; len(audio) > 0; {
+		n := min(streamChunkSize, len(audio))
+		if _, err := w.Write(audio[:n]); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		audio = audio[n:]
+	}
+}
+
+// synthesisText returns the text of a synthesis request or writes a validation error.
+func synthesisText(w http.ResponseWriter, req *Request) (string, bool) {
+	var body struct {
+		Text string `json:"text"`
+	}
+	if err := req.DecodeJSON(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON body")
+		return "", false
+	}
+	if strings.TrimSpace(body.Text) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_request", "text is required")
+		return "", false
+	}
+	return body.Text, true
+}
+
+func setAudioHeaders(w http.ResponseWriter, r *http.Request, text string) {
+	contentType := r.Header.Get("Accept")
+	if contentType == "" || contentType == "*/*" {
+		contentType = "audio/mpeg"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("x-character-count", strconv.Itoa(utf8.RuneCountInString(text)))
+}
+
+func (s *Server) serveSTT(w http.ResponseWriter, _ *http.Request, req *Request) {
+	if req.Form.Get("model_id") == "" {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_request", "model_id is required")
+		return
+	}
+	if _, ok := req.Files["file"]; !ok && req.Form.Get("source_url") == "" {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_request", "file or source_url is required")
+		return
+	}
+	language := req.Form.Get("language_code")
+	if language == "" {
+		language = "en"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(transcripts.TranscriptionResponse{
+		Text:                s.transcript,
+		LanguageCode:        language,
+		LanguageProbability: 1,
+		Words:               transcriptionWords(s.transcript),
+		TranscriptionID:     s.newID("tr"),
+	})
+}
+
+// wordDuration is the length of every word in fake transcripts, in seconds.
+const wordDuration = 0.5
+
+func transcriptionWords(text string) []transcripts.TranscriptionWord {
+	var words []transcripts.TranscriptionWord
+	for i, word := range strings.Fields(text) {
+		if i > 0 {
+			start := float64(i) * wordDuration
+			words = append(words, transcripts.TranscriptionWord{Text: " ", Start: start, End: start, Type: "spacing"})
+		}
+		start := float64(i) * wordDuration
+		words = append(words, transcripts.TranscriptionWord{Text: word, Start: start, End: start + wordDuration, Type: "word"})
+	}
+	return words
+}
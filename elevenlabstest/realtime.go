@@ -0,0 +1,289 @@
+package elevenlabstest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+// generationLength is the buffered text length at which RouteTTSRealtime generates audio without
+// a flush, like the first step of the default chunk length schedule.
+const generationLength = 120
+
+// charDurationMs is the length of every character in fake alignments.
+const charDurationMs = 50
+
+// Reply scripts what a realtime route sends in place of one default reply. RouteTTSRealtime
+// replies once per generation: when buffered text is flushed, triggered, reaches 120 characters
+// or the input ends. RouteSTTRealtime replies once per input_audio_chunk message.
+type Reply struct {
+	// Events are sent in order. Strings, byte slices and json.RawMessage are sent as they are;
+	// other values are encoded as JSON.
+	Events []any
+	// Delay is added to the latency of the server before the events are sent.
+	Delay time.Duration
+	// Close closes the session after the events with CloseStatus, which defaults to 1000,
+	// and CloseReason.
+	Close       bool
+	CloseStatus int
+	CloseReason string
+}
+
+// EnqueueReplies queues replies for a realtime route. Each default reply takes the next one;
+// once the queue is empty the route replies by default again.
+func (s *Server) EnqueueReplies(route Route, replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[route] = append(s.replies[route], replies...)
+}
+
+// TTSAudioEvent returns a realtime text-to-speech audio event with a character alignment of text.
+func TTSAudioEvent(audio []byte, text string) json.RawMessage {
+	event := map[string]any{"audio": base64.StdEncoding.EncodeToString(audio)}
+	if text != "" {
+		var alignment tts.Alignment
+		for i, r := range []rune(text) {
+			alignment.Chars = append(alignment.Chars, string(r))
+			alignment.CharStartTimesMs = append(alignment.CharStartTimesMs, i*charDurationMs)
+			alignment.CharDurationsMs = append(alignment.CharDurationsMs, charDurationMs)
+		}
+		event["alignment"] = alignment
+		event["normalizedAlignment"] = alignment
+	}
+	return mustMarshal(event)
+}
+
+// TTSFinalEvent returns the realtime text-to-speech event that ends a session.
+func TTSFinalEvent() json.RawMessage {
+	return json.RawMessage(`{"isFinal":true}`)
+}
+
+// TTSErrorEvent returns a realtime text-to-speech error event.
+func TTSErrorEvent(message string) json.RawMessage {
+	return mustMarshal(map[string]string{"error": message})
+}
+
+// STTErrorEvent returns a realtime speech-to-text error event of eventType, for example
+// transcripts.ServerEventQuotaExceededError.
+func STTErrorEvent(eventType transcripts.ServerEventType, message string) json.RawMessage {
+	return mustMarshal(transcripts.SpeechRecognitionCanceledEventArgs{
+		RecognitionEventArgs: transcripts.RecognitionEventArgs{Type: eventType},
+		Error:                message,
+	})
+}
+
+func mustMarshal(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// session is one realtime connection to the server.
+type session struct {
+	server *Server
+	route  Route
+	req    *Request
+	conn   *websocket.Conn
+}
+
+func (s *Server) accept(w http.ResponseWriter, r *http.Request, route Route, req *Request) (*session, bool) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return nil, false
+	}
+	return &session{server: s, route: route, req: req, conn: conn}, true
+}
+
+// read reads the next text frame and records it.
+func (ss *session) read(ctx context.Context) ([]byte, error) {
+	for {
+		messageType, data, err := ss.conn.Read(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if messageType != websocket.MessageText {
+			continue
+		}
+		ss.server.mu.Lock()
+		ss.req.Messages = append(ss.req.Messages, data)
+		ss.server.mu.Unlock()
+		return data, nil
+	}
+}
+
+func (ss *session) send(ctx context.Context, events ...any) error {
+	for _, event := range events {
+		var data []byte
+		switch v := event.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			var err error
+			if data, err = json.Marshal(v); err != nil {
+				return err
+			}
+		}
+		if err := ss.conn.Write(ctx, websocket.MessageText, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reply sends the next scripted reply of the route, or defaults when none is queued. It reports
+// whether the session was closed.
+func (ss *session) reply(ctx context.Context, defaults ...any) (bool, error) {
+	ss.server.mu.Lock()
+	reply := Reply{Events: defaults}
+	if queue := ss.server.replies[ss.route]; len(queue) > 0 {
+		reply, ss.server.replies[ss.route] = queue[0], queue[1:]
+	}
+	ss.server.mu.Unlock()
+
+	if err := ss.server.wait(ctx, reply.Delay); err != nil {
+		return false, err
+	}
+	if err := ss.send(ctx, reply.Events...); err != nil {
+		return false, err
+	}
+	if !reply.Close {
+		return false, nil
+	}
+	status := websocket.StatusNormalClosure
+	if reply.CloseStatus != 0 {
+		status = websocket.StatusCode(reply.CloseStatus)
+	}
+	return true, ss.conn.Close(status, reply.CloseReason)
+}
+
+func (s *Server) serveTTSRealtime(w http.ResponseWriter, r *http.Request, req *Request) {
+	ss, ok := s.accept(w, r, RouteTTSRealtime, req)
+	if !ok {
+		return
+	}
+	defer ss.conn.CloseNow()
+
+	ctx := r.Context()
+	var buffered strings.Builder
+	for {
+		data, err := ss.read(ctx)
+		if err != nil {
+			return
+		}
+		var msg struct {
+			Text                 *string `json:"text"`
+			Flush                bool    `json:"flush"`
+			TryTriggerGeneration bool    `json:"try_trigger_generation"`
+		}
+		if err = json.Unmarshal(data, &msg); err != nil || msg.Text == nil {
+			if err = ss.send(ctx, TTSErrorEvent("invalid message")); err != nil {
+				return
+			}
+			continue
+		}
+		end := *msg.Text == "" && !msg.Flush
+		if strings.TrimSpace(*msg.Text) != "" {
+			buffered.WriteString(*msg.Text)
+		}
+		if buffered.Len() > 0 && (msg.Flush || msg.TryTriggerGeneration || end || buffered.Len() >= generationLength) {
+			text := buffered.String()
+			buffered.Reset()
+			if closed, err := ss.reply(ctx, TTSAudioEvent(s.audio, text)); closed || err != nil {
+				return
+			}
+		}
+		if end {
+			if err = ss.send(ctx, TTSFinalEvent()); err == nil {
+				_ = ss.conn.Close(websocket.StatusNormalClosure, "")
+			}
+			return
+		}
+	}
+}
+
+func (s *Server) serveSTTRealtime(w http.ResponseWriter, r *http.Request, req *Request) {
+	ss, ok := s.accept(w, r, RouteSTTRealtime, req)
+	if !ok {
+		return
+	}
+	defer ss.conn.CloseNow()
+
+	ctx := r.Context()
+	query := r.URL.Query()
+	includeTimestamps := query.Get("include_timestamps") == "true"
+	started := transcripts.SessionStartEventArgs{
+		RecognitionEventArgs: transcripts.RecognitionEventArgs{Type: transcripts.ServerEventSessionStarted},
+		SessionID:            s.newID("sess"),
+		Config: transcripts.SessionStartConfig{
+			AudioFormat:       transcripts.AudioFormat(query.Get("audio_format")),
+			LanguageCode:      query.Get("language_code"),
+			CommitStrategy:    transcripts.CommitStrategy(query.Get("commit_strategy")),
+			ModelID:           query.Get("model_id"),
+			IncludeTimestamps: includeTimestamps,
+		},
+	}
+	if err := s.wait(ctx, 0); err != nil {
+		return
+	}
+	if err := ss.send(ctx, started); err != nil {
+		return
+	}
+
+	for {
+		data, err := ss.read(ctx)
+		if err != nil {
+			return
+		}
+		var msg struct {
+			Type   transcripts.ClientEventType `json:"message_type"`
+			Commit bool                        `json:"commit"`
+		}
+		if err = json.Unmarshal(data, &msg); err != nil || msg.Type != transcripts.ClientEventTypeSessionUpdate {
+			if err = ss.send(ctx, STTErrorEvent(transcripts.ServerEventInputError, "invalid message")); err != nil {
+				return
+			}
+			continue
+		}
+		events := []any{sttEvent(transcripts.ServerEventPartialTranscript, s.transcript)}
+		if msg.Commit {
+			events = []any{sttEvent(transcripts.ServerEventCommittedTranscript, s.transcript)}
+			if includeTimestamps {
+				events = append(events, transcripts.SpeechRecognizedWithTimestampEventArgs{
+					RecognitionEventArgs: transcripts.RecognitionEventArgs{Type: transcripts.ServerEventCommittedTranscriptWithTimestamps},
+					Text:                 s.transcript,
+					Language:             "en",
+					Words:                realtimeWords(s.transcript),
+				})
+			}
+		}
+		if closed, err := ss.reply(ctx, events...); closed || err != nil {
+			return
+		}
+	}
+}
+
+func sttEvent(eventType transcripts.ServerEventType, text string) transcripts.SpeechRecognizedEventArgs {
+	return transcripts.SpeechRecognizedEventArgs{
+		RecognitionEventArgs: transcripts.RecognitionEventArgs{Type: eventType},
+		Text:                 text,
+	}
+}
+
+func realtimeWords(text string) []transcripts.RealtimeTranscriptWord {
+	var words []transcripts.RealtimeTranscriptWord
+	for _, word := range transcriptionWords(text) {
+		words = append(words, transcripts.RealtimeTranscriptWord{Text: word.Text, Start: word.Start, End: word.End, Type: word.Type})
+	}
+	return words
+}
@@ -0,0 +1,380 @@
+// Package elevenlabstest provides an in-process fake of the ElevenLabs API for tests.
+//
+// The fake serves text-to-speech over HTTP, HTTP streaming and the realtime websocket, and
+// speech-to-text over HTTP and the realtime websocket. By default it answers like the real API
+// with configurable audio and transcript. Tests can queue scripted responses and replies, inject
+// errors and latency, and inspect every request the clients sent.
+package elevenlabstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+// Route identifies an endpoint of the fake server.
+type Route string
+
+const (
+	// RouteTTS is POST /v1/text-to-speech/{voice_id}.
+	RouteTTS Route = "text_to_speech"
+	// RouteTTSStream is POST /v1/text-to-speech/{voice_id}/stream.
+	RouteTTSStream Route = "text_to_speech.stream"
+	// RouteTTSRealtime is the websocket at /v1/text-to-speech/{voice_id}/stream-input.
+	RouteTTSRealtime Route = "text_to_speech.realtime"
+	// RouteSTT is POST /v1/speech-to-text.
+	RouteSTT Route = "speech_to_text"
+	// RouteSTTRealtime is the websocket at /v1/speech-to-text/realtime.
+	RouteSTTRealtime Route = "speech_to_text.realtime"
+)
+
+// DefaultTranscript is the text the fake transcribes any audio to.
+const DefaultTranscript = "hello world"
+
+// DefaultAudio is the audio the fake synthesizes: 100ms of silence in pcm_16000.
+var DefaultAudio = make([]byte, 3200)
+
+// Request is a request received by the fake server.
+type Request struct {
+	Route  Route
+	Method string
+	Path   string
+	// VoiceID is set for text-to-speech routes.
+	VoiceID string
+	Query   url.Values
+	Header  http.Header
+	Body    []byte
+	// Form holds the fields of a multipart body and Files the file contents by field name.
+	Form  url.Values
+	Files map[string][]byte
+	// Messages are the text frames the client sent on a realtime route, in order.
+	Messages [][]byte
+}
+
+// DecodeJSON decodes the body into v.
+func (r Request) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Response scripts the answer to one HTTP request or websocket handshake.
+type Response struct {
+	// Status is the status code. A zero Status with a nil Body keeps the default answer of the
+	// route and only applies Header and Delay.
+	Status int
+	Header http.Header
+	Body   []byte
+	// Delay is added to the latency of the server before answering.
+	Delay time.Duration
+	// Drop aborts the connection without an answer, like a network failure.
+	Drop bool
+}
+
+// ErrorResponse returns a Response with an ElevenLabs error body.
+func ErrorResponse(status int, code, message string) Response {
+	return Response{Status: status, Body: errorBody(code, message)}
+}
+
+// Server is a fake ElevenLabs API. Create it with NewServer and close it when done.
+type Server struct {
+	// URL is the base URL of the server, for example http://127.0.0.1:51234.
+	URL string
+
+	server     *httptest.Server
+	apiKey     string
+	audio      []byte
+	transcript string
+
+	mu        sync.Mutex
+	latency   time.Duration
+	responses map[Route][]Response
+	replies   map[Route][]Reply
+	requests  []*Request
+	nextID    int
+}
+
+type serverOptions struct {
+	apiKey     string
+	audio      []byte
+	transcript string
+	latency    time.Duration
+}
+
+// Option configures a Server.
+type Option func(*serverOptions)
+
+// WithAPIKey makes the server reject requests whose xi-api-key header or token query parameter
+// does not match key with a 401.
+func WithAPIKey(key string) Option {
+	return func(opts *serverOptions) {
+		opts.apiKey = key
+	}
+}
+
+// WithAudio sets the audio returned by text-to-speech routes. It defaults to DefaultAudio.
+func WithAudio(audio []byte) Option {
+	return func(opts *serverOptions) {
+		opts.audio = audio
+	}
+}
+
+// WithTranscript sets the text returned by speech-to-text routes. It defaults to DefaultTranscript.
+func WithTranscript(text string) Option {
+	return func(opts *serverOptions) {
+		opts.transcript = text
+	}
+}
+
+// WithLatency delays every HTTP answer, websocket handshake and websocket reply by d.
+func WithLatency(d time.Duration) Option {
+	return func(opts *serverOptions) {
+		opts.latency = d
+	}
+}
+
+// NewServer starts a fake server.
+func NewServer(opts ...Option) *Server {
+	options := serverOptions{audio: DefaultAudio, transcript: DefaultTranscript}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	s := &Server{
+		apiKey:     options.apiKey,
+		audio:      options.audio,
+		transcript: options.transcript,
+		latency:    options.latency,
+		responses:  make(map[Route][]Response),
+		replies:    make(map[Route][]Reply),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /v1/text-to-speech/{voice_id}", s.handle(RouteTTS, s.serveTTS))
+	mux.Handle("POST /v1/text-to-speech/{voice_id}/stream", s.handle(RouteTTSStream, s.serveTTSStream))
+	mux.Handle("GET /v1/text-to-speech/{voice_id}/stream-input", s.handle(RouteTTSRealtime, s.serveTTSRealtime))
+	mux.Handle("POST /v1/speech-to-text", s.handle(RouteSTT, s.serveSTT))
+	mux.Handle("GET /v1/speech-to-text/realtime", s.handle(RouteSTTRealtime, s.serveSTTRealtime))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not_found", "no fake for "+r.Method+" "+r.URL.Path)
+	})
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down and closes open websocket sessions.
+func (s *Server) Close() {
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+// TTSConfig returns a tts client config that talks to the server.
+func (s *Server) TTSConfig(apiKey string) tts.ClientConfig {
+	cfg := tts.DefaultConfig(apiKey)
+	cfg.BaseURL = s.URL
+	return cfg
+}
+
+// TranscriptsConfig returns a transcripts client config that talks to the server.
+func (s *Server) TranscriptsConfig(apiKey string) transcripts.ClientConfig {
+	cfg := transcripts.DefaultConfig(apiKey)
+	cfg.BaseURL = "ws" + strings.TrimPrefix(s.URL, "http") + "/v1/speech-to-text/realtime"
+	cfg.HTTPBaseURL = s.URL + "/v1/speech-to-text"
+	return cfg
+}
+
+// SetLatency changes the latency set by WithLatency.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Enqueue queues responses for route. Each request to route takes the next one; once the
+// queue is empty the route answers by default again.
+func (s *Server) Enqueue(route Route, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[route] = append(s.responses[route], responses...)
+}
+
+// Requests returns the requests received on route, oldest first.
+func (s *Server) Requests(route Route) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var requests []Request
+	for _, req := range s.requests {
+		if req.Route == route {
+			copied := *req
+			copied.Messages = append([][]byte(nil), req.Messages...)
+			requests = append(requests, copied)
+		}
+	}
+	return requests
+}
+
+// LastRequest returns the latest request received on route.
+func (s *Server) LastRequest(route Route) (Request, bool) {
+	requests := s.Requests(route)
+	if len(requests) == 0 {
+		return Request{}, false
+	}
+	return requests[len(requests)-1], true
+}
+
+// handle records the request, applies scripted responses, latency and authentication, and then
+// calls serve.
+func (s *Server) handle(route Route, serve func(http.ResponseWriter, *http.Request, *Request)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := s.record(route, r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		resp, scripted := s.nextResponse(route)
+		if err = s.wait(r.Context(), resp.Delay); err != nil {
+			return
+		}
+		if resp.Drop {
+			panic(http.ErrAbortHandler)
+		}
+
+		w.Header().Set("request-id", s.newID("req"))
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		if scripted && (resp.Status != 0 || resp.Body != nil) {
+			status := resp.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusBadRequest && w.Header().Get("Content-Type") == "" {
+				w.Header().Set("Content-Type", "application/json")
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write(resp.Body)
+			return
+		}
+		if !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API key")
+			return
+		}
+		serve(w, r, req)
+	})
+}
+
+func (s *Server) record(route Route, r *http.Request) (*Request, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	req := &Request{
+		Route:   route,
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		VoiceID: r.PathValue("voice_id"),
+		Query:   r.URL.Query(),
+		Header:  r.Header.Clone(),
+		Body:    body,
+	}
+	// The form is parsed before the request is shared, since Requests copies it.
+	err = parseMultipart(req, r.Header.Get("Content-Type"))
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	return req, err
+}
+
+// parseMultipart fills Form and Files from a multipart/form-data body.
+func parseMultipart(req *Request, contentType string) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil
+	}
+	form, err := multipart.NewReader(bytes.NewReader(req.Body), params["boundary"]).ReadForm(32 << 20)
+	if err != nil {
+		return fmt.Errorf("parse multipart body: %w", err)
+	}
+	defer form.RemoveAll()
+	req.Form = url.Values(form.Value)
+	req.Files = make(map[string][]byte)
+	for name, headers := range form.File {
+		file, err := headers[0].Open()
+		if err != nil {
+			return err
+		}
+		req.Files[name], err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) nextResponse(route Route) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue := s.responses[route]
+	if len(queue) == 0 {
+		return Response{}, false
+	}
+	s.responses[route] = queue[1:]
+	return queue[0], true
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.apiKey == "" {
+		return true
+	}
+	return r.Header.Get("xi-api-key") == s.apiKey || r.URL.Query().Get("token") == s.apiKey
+}
+
+// wait sleeps for the latency of the server plus extra.
+func (s *Server) wait(ctx context.Context, extra time.Duration) error {
+	s.mu.Lock()
+	d := s.latency + extra
+	s.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) newID(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return fmt.Sprintf("%s_%d", prefix, s.nextID)
+}
+
+func errorBody(code, message string) []byte {
+	body, _ := json.Marshal(map[string]any{
+		"detail": map[string]string{"status": code, "message": message},
+	})
+	return body
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(errorBody(code, message))
+}
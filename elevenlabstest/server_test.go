@@ -0,0 +1,241 @@
+package elevenlabstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+func TestServerSynthesizesOverHTTP(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(WithAudio([]byte("fake-audio")))
+	defer server.Close()
+	client := tts.NewClientWithConfig(server.TTSConfig("test-key"))
+
+	resp, err := client.Synthesize(context.Background(), tts.SynthesisRequest{VoiceID: "voice_123", Text: "Hello there."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(resp.Audio), "fake-audio"; got != want {
+		t.Errorf("audio = %q, want %q", got, want)
+	}
+	if got, want := resp.CharacterCount, "12"; got != want {
+		t.Errorf("character count = %q, want %q", got, want)
+	}
+	if resp.RequestID == "" {
+		t.Error("request id is empty")
+	}
+
+	stream, err := client.StreamAudio(context.Background(), tts.SynthesisRequest{VoiceID: "voice_456", Text: "Hi."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio, err := io.ReadAll(stream.Audio)
+	_ = stream.Audio.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(audio), "fake-audio"; got != want {
+		t.Errorf("streamed audio = %q, want %q", got, want)
+	}
+
+	req, ok := server.LastRequest(RouteTTS)
+	if !ok {
+		t.Fatal("no text_to_speech request recorded")
+	}
+	var body tts.SynthesisRequest
+	if err = req.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if req.VoiceID != "voice_123" || body.Text != "Hello there." || req.Header.Get("xi-api-key") != "test-key" {
+		t.Errorf("request = voice %q, text %q, key %q", req.VoiceID, body.Text, req.Header.Get("xi-api-key"))
+	}
+	if got, want := len(server.Requests(RouteTTSStream)), 1; got != want {
+		t.Errorf("stream requests = %d, want %d", got, want)
+	}
+}
+
+func TestServerInjectsErrorsAndLatency(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(WithAPIKey("test-key"))
+	defer server.Close()
+	req := tts.SynthesisRequest{VoiceID: "voice_123", Text: "Hi."}
+
+	var apiErr *tts.APIError
+	_, err := tts.NewClientWithConfig(server.TTSConfig("wrong-key")).Synthesize(context.Background(), req)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong key error = %v, want a 401 APIError", err)
+	}
+
+	client := tts.NewClientWithConfig(server.TTSConfig("test-key"))
+	server.Enqueue(RouteTTS,
+		ErrorResponse(http.StatusTooManyRequests, "too_many_concurrent_requests", "slow down"),
+		Response{Drop: true},
+		Response{Delay: 30 * time.Millisecond},
+	)
+	_, err = client.Synthesize(context.Background(), req)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Message != "slow down" {
+		t.Fatalf("scripted error = %v, want a 429 APIError", err)
+	}
+	if _, err = client.Synthesize(context.Background(), req); err == nil {
+		t.Fatal("dropped request succeeded")
+	}
+	start := time.Now()
+	if _, err = client.Synthesize(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("delayed request took %v, want at least 30ms", elapsed)
+	}
+}
+
+func TestServerStreamsRealtimeSynthesis(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(WithAudio([]byte("abc")))
+	defer server.Close()
+	server.EnqueueReplies(RouteTTSRealtime, Reply{Events: []any{TTSAudioEvent([]byte("xyz"), "Hello "), TTSErrorEvent("quota exceeded")}})
+
+	conn, err := tts.NewClientWithConfig(server.TTSConfig("test-key")).ConnectRealtime(context.Background(), tts.StreamInputRequest{VoiceID: "voice_123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audio bytes.Buffer
+	var errorEvents []string
+	streamer := tts.NewRealtimeSynthesizer(context.Background(), conn, func(_ context.Context, event tts.StreamEvent) {
+		switch e := event.(type) {
+		case tts.AudioEvent:
+			audio.Write(e.Audio)
+		case tts.ErrorEvent:
+			errorEvents = append(errorEvents, e.Message)
+		}
+	})
+	streamer.Start()
+
+	for _, send := range []func() error{
+		func() error { return streamer.SendText("Hello ") },
+		streamer.Flush,
+		func() error { return streamer.SendText("world. ") },
+		streamer.CloseInput,
+	} {
+		if err = send(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = streamer.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = streamer.Close()
+
+	if got, want := audio.String(), "xyzabc"; got != want {
+		t.Errorf("audio = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(errorEvents, ","), "quota exceeded"; got != want {
+		t.Errorf("error events = %q, want %q", got, want)
+	}
+	req, _ := server.LastRequest(RouteTTSRealtime)
+	if got, want := len(req.Messages), 5; got != want {
+		t.Fatalf("messages = %d, want %d", got, want)
+	}
+	if got, want := string(req.Messages[4]), `{"text":""}`; got != want {
+		t.Errorf("last message = %s, want %s", got, want)
+	}
+}
+
+func TestServerTranscribesOverHTTP(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(WithTranscript("good morning"))
+	defer server.Close()
+
+	resp, err := transcripts.NewClientWithConfig(server.TranscriptsConfig("test-key")).Transcribe(context.Background(), transcripts.TranscriptionRequest{
+		ModelID:  "scribe_v1",
+		FileName: "sample.wav",
+		File:     strings.NewReader("audio"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Text, "good morning"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if got, want := len(resp.Words), 3; got != want {
+		t.Errorf("words = %d, want %d", got, want)
+	}
+	req, _ := server.LastRequest(RouteSTT)
+	if got, want := string(req.Files["file"]), "audio"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+	if got, want := req.Form.Get("model_id"), "scribe_v1"; got != want {
+		t.Errorf("model_id = %q, want %q", got, want)
+	}
+}
+
+func TestServerStreamsRealtimeTranscription(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	conn, err := transcripts.NewClientWithConfig(server.TranscriptsConfig("test-key")).Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	read := func() transcripts.ServerEvent {
+		t.Helper()
+		event, err := conn.ReadMessage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+	send := func(commit bool) {
+		t.Helper()
+		if err := conn.SendMessage(ctx, transcripts.InputAudioChunkEvent{Audio: "AAAA", Commit: commit, SampleRate: 16000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if started, ok := read().(transcripts.SessionStartEventArgs); !ok || started.SessionID == "" {
+		t.Fatalf("first event = %#v, want session_started", started)
+	}
+	send(false)
+	if partial, ok := read().(transcripts.SpeechRecognizingEventArgs); !ok || partial.Text != DefaultTranscript {
+		t.Errorf("partial = %#v, want %q", partial, DefaultTranscript)
+	}
+	send(true)
+	if committed, ok := read().(transcripts.SpeechRecognizedEventArgs); !ok || committed.Text != DefaultTranscript {
+		t.Errorf("committed = %#v, want %q", committed, DefaultTranscript)
+	}
+	if withTimestamps, ok := read().(transcripts.SpeechRecognizedWithTimestampEventArgs); !ok || len(withTimestamps.Words) != 3 {
+		t.Errorf("committed with timestamps = %#v, want 3 words", withTimestamps)
+	}
+
+	server.EnqueueReplies(RouteSTTRealtime, Reply{
+		Events: []any{STTErrorEvent(transcripts.ServerEventQuotaExceededError, "quota exceeded")},
+		Close:  true,
+	})
+	send(false)
+	if canceled, ok := read().(transcripts.SpeechRecognitionCanceledEventArgs); !ok || canceled.Error != "quota exceeded" {
+		t.Errorf("error event = %#v, want quota_exceeded", canceled)
+	}
+	if _, err = conn.ReadMessage(ctx); err == nil {
+		t.Error("read after the scripted close succeeded")
+	}
+	req, _ := server.LastRequest(RouteSTTRealtime)
+	if got, want := len(req.Messages), 3; got != want {
+		t.Errorf("messages = %d, want %d", got, want)
+	}
+}
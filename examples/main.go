@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"time"
@@ -58,38 +57,35 @@ func main() {
 			}
 		})
 
+	writer := recognizer.AudioWriter(transcripts.AudioWriterOptions{
+		ChunkDuration: 300 * time.Millisecond,
+		Realtime:      true,
+	})
+
 	recognizer.Start()
-	defer func() {
-		if err = recognizer.Stop(); err != nil {
-			logger.Errorf("stop continuous recognition error: %+v\n", err)
-			return
-		}
-		logger.Debugf("stop continuous recognition done.\n")
-	}()
 
 	logger.Debugf("Mock send pcm stream...\n")
-	interval := 300 * time.Millisecond
-	chunkSize := int(16000 * 2 * interval.Seconds())
-	if err = StreamPCMWithChannel(ctx, recognizer, "./examples/simple/nicole.pcm", chunkSize, interval); err != nil {
+	if err = StreamPCMFile(writer, "./examples/simple/nicole.pcm"); err != nil {
 		logger.Errorf("stream PCM error: %+v\n", err)
 		return
 	}
 	logger.Debugf("send data done...\n")
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err = <-recognizer.Err():
-			logger.Errorf("conn handler error: %+v\n", err)
-			return
-		case <-time.After(20 * time.Second):
-			return
-		}
+	finishCtx, finishCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer finishCancel()
+	if err = recognizer.Finish(finishCtx); err != nil {
+		logger.Errorf("finish recognition error: %+v\n", err)
+		return
+	}
+	if err = <-recognizer.Err(); err != nil {
+		logger.Errorf("conn handler error: %+v\n", err)
+		return
 	}
+	logger.Debugf("finish recognition done.\n")
 }
 
-func StreamPCMWithChannel(ctx context.Context, recognizer *transcripts.Recognizer, pcmFile string, chunkSize int, interval time.Duration) error {
+// StreamPCMFile sends a raw PCM file at realtime pace and commits at EOF.
+func StreamPCMFile(writer *transcripts.AudioWriter, pcmFile string) error {
 	file, err := os.Open(pcmFile)
 	if err != nil {
 		return err
@@ -100,33 +96,10 @@ func StreamPCMWithChannel(ctx context.Context, recognizer *transcripts.Recognize
 		}
 	}()
 
-	buffer := make([]byte, chunkSize)
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			var n int
-			if n, err = file.Read(buffer); n > 0 {
-				if sendErr := recognizer.Send(buffer[:n]); sendErr != nil {
-					return fmt.Errorf("failed to send audio chunk: %w", sendErr)
-				}
-				log.Printf("Sent %d bytes\n", n)
-			}
-
-			if err != nil {
-				if err == io.EOF {
-					if sendErr := recognizer.Commit(); sendErr != nil {
-						return fmt.Errorf("failed to commit audio: %w", sendErr)
-					}
-					log.Println("Finished sending PCM data")
-					return nil
-				}
-				return fmt.Errorf("error reading PCM file: %w", err)
-			}
-		}
+	n, err := writer.ReadFrom(file)
+	if err != nil {
+		return fmt.Errorf("failed to stream audio: %w", err)
 	}
+	log.Printf("Finished sending %d bytes of PCM data\n", n)
+	return nil
 }
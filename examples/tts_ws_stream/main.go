@@ -47,16 +47,9 @@ func main() {
 	if err = streamer.SendText("This is the second sentence."); err != nil {
 		log.Fatalf("send second chunk: %v", err)
 	}
-	if err = streamer.Flush(); err != nil {
-		log.Fatalf("flush stream: %v", err)
-	}
-
-	select {
-	case err = <-streamer.Err():
-		if err != nil {
-			log.Fatalf("streamer error: %v", err)
-		}
-	case <-time.After(10 * time.Second):
-		log.Println("stream timeout")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err = streamer.Shutdown(ctx); err != nil {
+		log.Fatalf("shutdown stream: %v", err)
 	}
 }
@@ -0,0 +1,82 @@
+// Package g711 implements the G.711 μ-law and A-law codecs. It is shared by the audio and transcripts
+// packages, which cannot import each other both ways.
+package g711
+
+const (
+	mulawBias = 0x84
+	mulawClip = 32635
+)
+
+// MulawEncode encodes a 16-bit linear sample as G.711 μ-law.
+func MulawEncode(sample int16) byte {
+	s := int(sample)
+	var sign int
+	if s < 0 {
+		sign = 0x80
+		s = -s
+	}
+	if s > mulawClip {
+		s = mulawClip
+	}
+	s += mulawBias
+	exponent := 7
+	for mask := 0x4000; s&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := (s >> (exponent + 3)) & 0x0f
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+// MulawDecode decodes a G.711 μ-law byte to a 16-bit linear sample.
+func MulawDecode(u byte) int16 {
+	u = ^u
+	t := (int(u&0x0f) << 3) + mulawBias
+	t <<= (u & 0x70) >> 4
+	if u&0x80 != 0 {
+		return int16(mulawBias - t)
+	}
+	return int16(t - mulawBias)
+}
+
+// AlawEncode encodes a 16-bit linear sample as G.711 A-law.
+func AlawEncode(sample int16) byte {
+	s := int(sample) >> 3
+	mask := 0xd5
+	if s < 0 {
+		mask = 0x55
+		s = -s - 1
+	}
+	segment := 0
+	for end := 0x1f; segment < 8 && s > end; end = end<<1 | 1 {
+		segment++
+	}
+	if segment >= 8 {
+		return byte(0x7f ^ mask)
+	}
+	a := segment << 4
+	if segment < 2 {
+		a |= (s >> 1) & 0x0f
+	} else {
+		a |= (s >> segment) & 0x0f
+	}
+	return byte(a ^ mask)
+}
+
+// AlawDecode decodes a G.711 A-law byte to a 16-bit linear sample.
+func AlawDecode(a byte) int16 {
+	a ^= 0x55
+	t := int(a&0x0f) << 4
+	switch segment := (a & 0x70) >> 4; segment {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= segment - 1
+	}
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
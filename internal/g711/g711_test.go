@@ -0,0 +1,13 @@
+package g711
+
+import "testing"
+
+func TestMulawDecode(t *testing.T) {
+	t.Parallel()
+
+	for u, want := range map[byte]int16{0xff: 0, 0x7f: 0, 0x80: 32124, 0x00: -32124} {
+		if got := MulawDecode(u); got != want {
+			t.Errorf("MulawDecode(%#x) = %d, want %d", u, got, want)
+		}
+	}
+}
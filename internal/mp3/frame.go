@@ -0,0 +1,291 @@
+// Package mp3 reads the frames of MPEG audio streams without decoding them. It is shared by the
+// audio and tts packages, which cannot import each other both ways.
+package mp3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrInvalid is returned when a stream contains no MP3 frames.
+var ErrInvalid = errors.New("invalid MP3 data")
+
+// Header is a decoded MPEG audio frame header.
+type Header struct {
+	// Version is 1 for MPEG-1, 2 for MPEG-2 and 25 for MPEG-2.5.
+	Version int
+	// Layer is 1, 2 or 3.
+	Layer      int
+	Bitrate    int
+	SampleRate int
+	Channels   int
+	Padding    bool
+}
+
+var bitrates = [5][15]int{
+	{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448}, // MPEG-1 layer I
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},    // MPEG-1 layer II
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},     // MPEG-1 layer III
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},    // MPEG-2/2.5 layer I
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},         // MPEG-2/2.5 layer II and III
+}
+
+// ParseHeader decodes the four header bytes of an MPEG audio frame.
+// Free-format bitrates are not supported.
+func ParseHeader(b []byte) (Header, error) {
+	if len(b) < 4 || b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+		return Header{}, fmt.Errorf("%w: no frame sync", ErrInvalid)
+	}
+	var h Header
+	switch (b[1] >> 3) & 0x03 {
+	case 0:
+		h.Version = 25
+	case 2:
+		h.Version = 2
+	case 3:
+		h.Version = 1
+	default:
+		return Header{}, fmt.Errorf("%w: reserved version", ErrInvalid)
+	}
+	layerBits := (b[1] >> 1) & 0x03
+	if layerBits == 0 {
+		return Header{}, fmt.Errorf("%w: reserved layer", ErrInvalid)
+	}
+	h.Layer = 4 - int(layerBits)
+
+	bitrateIndex := b[2] >> 4
+	sampleRateIndex := (b[2] >> 2) & 0x03
+	if bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return Header{}, fmt.Errorf("%w: unsupported bitrate or sample rate", ErrInvalid)
+	}
+	table := h.Layer - 1
+	if h.Version != 1 {
+		table = min(3+h.Layer-1, 4)
+	}
+	h.Bitrate = bitrates[table][bitrateIndex] * 1000
+	h.SampleRate = [3]int{44100, 48000, 32000}[sampleRateIndex]
+	switch h.Version {
+	case 2:
+		h.SampleRate /= 2
+	case 25:
+		h.SampleRate /= 4
+	}
+	h.Padding = b[2]&0x02 != 0
+	h.Channels = 2
+	if b[3]>>6 == 3 {
+		h.Channels = 1
+	}
+	return h, nil
+}
+
+// FrameSize returns the size of the frame in bytes, including the header.
+func (h Header) FrameSize() int {
+	var padding int
+	if h.Padding {
+		padding = 1
+	}
+	switch {
+	case h.Layer == 1:
+		return (12*h.Bitrate/h.SampleRate + padding) * 4
+	case h.Layer == 3 && h.Version != 1:
+		return 72*h.Bitrate/h.SampleRate + padding
+	default:
+		return 144*h.Bitrate/h.SampleRate + padding
+	}
+}
+
+// Samples returns the number of samples per channel the frame decodes to.
+func (h Header) Samples() int {
+	switch {
+	case h.Layer == 1:
+		return 384
+	case h.Layer == 3 && h.Version != 1:
+		return 576
+	default:
+		return 1152
+	}
+}
+
+// Duration returns the playing time of the frame.
+func (h Header) Duration() time.Duration {
+	return time.Duration(h.Samples()) * time.Second / time.Duration(h.SampleRate)
+}
+
+// Frame is one undecoded MPEG audio frame.
+type Frame struct {
+	Header Header
+	// Data is the whole frame, including the header.
+	Data []byte
+}
+
+// xingOffset returns where a Xing/Info tag would start in the frame.
+func (f Frame) xingOffset() int {
+	switch {
+	case f.Header.Version == 1 && f.Header.Channels == 1:
+		return 4 + 17
+	case f.Header.Version == 1:
+		return 4 + 32
+	case f.Header.Channels == 1:
+		return 4 + 9
+	default:
+		return 4 + 17
+	}
+}
+
+// IsInfo reports whether the frame is a Xing, Info or VBRI tag frame instead of audio.
+// Tag frames decode to silence and must not be repeated in the middle of a stream.
+func (f Frame) IsInfo() bool {
+	if off := f.xingOffset(); len(f.Data) >= off+4 {
+		if tag := string(f.Data[off : off+4]); tag == "Xing" || tag == "Info" {
+			return true
+		}
+	}
+	return len(f.Data) >= 40 && string(f.Data[36:40]) == "VBRI"
+}
+
+// Gapless returns the encoder delay and padding of a LAME tag in an Info frame.
+func (f Frame) Gapless() (delay, padding int, ok bool) {
+	off := f.xingOffset()
+	if len(f.Data) < off+8 {
+		return 0, 0, false
+	}
+	flags := binary.BigEndian.Uint32(f.Data[off+4:])
+	lame := off + 8
+	for _, field := range []struct {
+		bit  uint32
+		size int
+	}{{1, 4}, {2, 4}, {4, 100}, {8, 4}} {
+		if flags&field.bit != 0 {
+			lame += field.size
+		}
+	}
+	// The LAME extension stores 12-bit delay and padding values 21 bytes after its version string.
+	if len(f.Data) < lame+24 || !bytes.HasPrefix(f.Data[lame:], []byte("LAME")) && !bytes.HasPrefix(f.Data[lame:], []byte("Lavc")) {
+		return 0, 0, false
+	}
+	v := f.Data[lame+21:]
+	delay = int(v[0])<<4 | int(v[1])>>4
+	padding = int(v[1]&0x0f)<<8 | int(v[2])
+	return delay, padding, true
+}
+
+// Reader reads the frames of an MP3 stream without decoding them.
+// ID3 tags and bytes that do not belong to a frame are skipped. A frame header is only accepted
+// when the next frame header, a tag or the end of the stream follows the frame.
+type Reader struct {
+	r      *bufio.Reader
+	frames int
+}
+
+// NewReader creates a Reader.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 8192)}
+}
+
+// Next returns the next frame, or io.EOF at the end of the stream.
+func (r *Reader) Next() (Frame, error) {
+	for {
+		head, err := r.r.Peek(10)
+		if len(head) < 4 {
+			if r.frames == 0 {
+				return Frame{}, fmt.Errorf("%w: no frames found", ErrInvalid)
+			}
+			if err == nil || errors.Is(err, io.EOF) {
+				err = io.EOF
+			}
+			return Frame{}, err
+		}
+		if skip := tagSize(head); skip > 0 {
+			if _, err := r.r.Discard(skip); err != nil {
+				return Frame{}, io.EOF
+			}
+			continue
+		}
+		header, herr := ParseHeader(head)
+		if herr != nil || !r.synced(header) {
+			_, _ = r.r.Discard(1)
+			continue
+		}
+		data := make([]byte, header.FrameSize())
+		n, err := io.ReadFull(r.r, data)
+		if err != nil {
+			if r.frames == 0 && n < len(data) {
+				return Frame{}, fmt.Errorf("%w: truncated frame", ErrInvalid)
+			}
+			// A truncated last frame is dropped.
+			return Frame{}, io.EOF
+		}
+		r.frames++
+		return Frame{Header: header, Data: data}, nil
+	}
+}
+
+// synced reports whether the frame starting with header is followed by a frame of the same version,
+// layer and sample rate, by a tag, or by the end of the stream. A frame sync pattern that occurs by
+// chance in other data rarely is.
+func (r *Reader) synced(header Header) bool {
+	size := header.FrameSize()
+	data, _ := r.r.Peek(size + 4)
+	if len(data) < size+4 {
+		return true
+	}
+	next := data[size:]
+	if string(next[:3]) == "ID3" || string(next[:3]) == "TAG" {
+		return true
+	}
+	following, err := ParseHeader(next)
+	return err == nil && following.Version == header.Version && following.Layer == header.Layer &&
+		following.SampleRate == header.SampleRate
+}
+
+// tagSize returns the size of an ID3v2 or ID3v1 tag starting at head, or 0.
+func tagSize(head []byte) int {
+	if len(head) >= 10 && string(head[:3]) == "ID3" {
+		size := int(head[6]&0x7f)<<21 | int(head[7]&0x7f)<<14 | int(head[8]&0x7f)<<7 | int(head[9]&0x7f)
+		if head[5]&0x10 != 0 {
+			size += 10
+		}
+		return 10 + size
+	}
+	if string(head[:3]) == "TAG" {
+		return 128
+	}
+	return 0
+}
+
+// Concat writes the audio frames of several clips to w as one stream. Tags and Xing/Info frames are
+// dropped, since a tag frame in the middle of a stream plays as a gap. A clip whose sample rate or
+// channel count differs from the first one's fails with mismatch.
+func Concat(w io.Writer, mismatch error, clips ...io.Reader) error {
+	var first *Header
+	for i, clip := range clips {
+		reader := NewReader(clip)
+		for {
+			frame, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("clip %d: %w", i, err)
+			}
+			if frame.IsInfo() {
+				continue
+			}
+			if first == nil {
+				first = &frame.Header
+			} else if frame.Header.SampleRate != first.SampleRate || frame.Header.Channels != first.Channels {
+				return fmt.Errorf("%w: clip %d is %d Hz with %d channels, want %d Hz with %d channels",
+					mismatch, i, frame.Header.SampleRate, frame.Header.Channels, first.SampleRate, first.Channels)
+			}
+			if _, err := w.Write(frame.Data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+module github.com/gouyuwang/go-elevenlabs/otelelevenlabs
+
+go 1.23.0
+
+require (
+	github.com/coder/websocket v1.8.14
+	github.com/gouyuwang/go-elevenlabs v0.0.0-20261018225425-92986409c9ef
+	go.opentelemetry.io/otel v1.37.0
+	go.opentelemetry.io/otel/metric v1.37.0
+	go.opentelemetry.io/otel/sdk v1.37.0
+	go.opentelemetry.io/otel/sdk/metric v1.37.0
+	go.opentelemetry.io/otel/trace v1.37.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+)
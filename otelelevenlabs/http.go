@@ -0,0 +1,101 @@
+package otelelevenlabs
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport returns an http.RoundTripper that traces every call made through base.
+// A nil base means http.DefaultTransport. The span ends when the response body is closed or fully read.
+func (i *Instrumentation) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, inst: i}
+}
+
+type transport struct {
+	base http.RoundTripper
+	inst *Instrumentation
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := operation(req.URL.Path)
+	ctx, span := t.inst.tracer.Start(req.Context(), "elevenlabs "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttrOperation.String(op),
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", redactURL(req.URL)),
+			attribute.String("server.address", req.URL.Hostname()),
+		))
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if requestID := resp.Header.Get("request-id"); requestID != "" {
+		span.SetAttributes(AttrRequestID.String(requestID))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	if count, err := strconv.ParseInt(characterCount(resp.Header), 10, 64); err == nil {
+		span.SetAttributes(attribute.Int64("elevenlabs.character_count", count))
+		t.inst.characters.Add(ctx, count, metric.WithAttributes(AttrOperation.String(op)))
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		span.End()
+		return resp, nil
+	}
+	resp.Body = &spanBody{ReadCloser: resp.Body, span: span}
+	return resp, nil
+}
+
+func characterCount(header http.Header) string {
+	if value := header.Get("x-character-count"); value != "" {
+		return value
+	}
+	return header.Get("character-cost")
+}
+
+// spanBody ends the span at EOF, on a read error or on Close.
+type spanBody struct {
+	io.ReadCloser
+	span trace.Span
+	once sync.Once
+}
+
+func (b *spanBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			b.span.RecordError(err)
+			b.span.SetStatus(codes.Error, err.Error())
+		}
+		b.end()
+	}
+	return n, err
+}
+
+func (b *spanBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end()
+	return err
+}
+
+func (b *spanBody) end() {
+	b.once.Do(func() { b.span.End() })
+}
@@ -0,0 +1,162 @@
+// Package otelelevenlabs adds OpenTelemetry tracing and metrics to the tts and transcripts clients.
+//
+// It is a separate module so that the core packages do not depend on OpenTelemetry.
+// Instrumentation is an HTTPMiddleware and a WebSocketMiddleware, which wrap whatever transport and
+// dialer the client uses. One call adds both:
+//
+//	inst, err := otelelevenlabs.New()
+//	client := tts.NewClientWithConfig(inst.TTSConfig(tts.DefaultConfig(apiKey)))
+//	conn, err := client.ConnectRealtime(ctx, req)
+package otelelevenlabs
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter.
+const ScopeName = "github.com/gouyuwang/go-elevenlabs/otelelevenlabs"
+
+// Attribute keys set by this package.
+const (
+	AttrRequestID = attribute.Key("elevenlabs.request_id")
+	AttrOperation = attribute.Key("elevenlabs.operation")
+	AttrEventType = attribute.Key("elevenlabs.event_type")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures Instrumentation.
+type Option func(*config)
+
+// WithTracerProvider sets the TracerProvider. It defaults to the global provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the MeterProvider. It defaults to the global provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// Instrumentation creates instrumented HTTP clients and websocket dialers. It records:
+//
+//   - a client span for every HTTP call, with the request-id response header and the status code
+//   - a span for every websocket session, from dial to close, with message counts per event type
+//     and a span event for every error event
+//   - the elevenlabs.characters counter, from the character count headers of TTS responses
+//   - the elevenlabs.websocket.events counter, per event type
+type Instrumentation struct {
+	tracer     trace.Tracer
+	characters metric.Int64Counter
+	events     metric.Int64Counter
+}
+
+// New creates Instrumentation.
+func New(opts ...Option) (*Instrumentation, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	meter := cfg.meterProvider.Meter(ScopeName)
+	characters, err := meter.Int64Counter("elevenlabs.characters",
+		metric.WithUnit("{character}"),
+		metric.WithDescription("Characters billed for text to speech requests."))
+	if err != nil {
+		return nil, err
+	}
+	events, err := meter.Int64Counter("elevenlabs.websocket.events",
+		metric.WithUnit("{event}"),
+		metric.WithDescription("Events received on websocket sessions."))
+	if err != nil {
+		return nil, err
+	}
+	return &Instrumentation{
+		tracer:     cfg.tracerProvider.Tracer(ScopeName),
+		characters: characters,
+		events:     events,
+	}, nil
+}
+
+// TTSConfig returns cfg with the HTTP and websocket middlewares added, outermost.
+func (i *Instrumentation) TTSConfig(cfg tts.ClientConfig) tts.ClientConfig {
+	cfg.HTTPMiddleware = append([]transcripts.HTTPMiddleware{i.HTTPMiddleware()}, cfg.HTTPMiddleware...)
+	cfg.WebSocketMiddleware = append([]transcripts.WebSocketMiddleware{i.WebSocketMiddleware()}, cfg.WebSocketMiddleware...)
+	return cfg
+}
+
+// TranscriptsConfig returns cfg with the HTTP and websocket middlewares added, outermost.
+func (i *Instrumentation) TranscriptsConfig(cfg transcripts.ClientConfig) transcripts.ClientConfig {
+	cfg.HTTPMiddleware = append([]transcripts.HTTPMiddleware{i.HTTPMiddleware()}, cfg.HTTPMiddleware...)
+	cfg.WebSocketMiddleware = append([]transcripts.WebSocketMiddleware{i.WebSocketMiddleware()}, cfg.WebSocketMiddleware...)
+	return cfg
+}
+
+// HTTPMiddleware returns a middleware that traces every HTTP call with Transport.
+func (i *Instrumentation) HTTPMiddleware() transcripts.HTTPMiddleware {
+	return i.Transport
+}
+
+// HTTPClient returns a copy of base whose transport is instrumented. A nil base means http.DefaultClient.
+func (i *Instrumentation) HTTPClient(base *http.Client) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	client := *base
+	client.Transport = i.Transport(base.Transport)
+	return &client
+}
+
+// operation names the API called at path with a low-cardinality value.
+func operation(path string) string {
+	switch {
+	case strings.HasSuffix(path, "/multi-stream-input"):
+		return "text_to_speech.realtime_multi_context"
+	case strings.HasSuffix(path, "/stream-input"):
+		return "text_to_speech.realtime"
+	case strings.HasPrefix(path, "/v1/text-to-speech/") && strings.HasSuffix(path, "/stream"):
+		return "text_to_speech.stream"
+	case strings.HasPrefix(path, "/v1/text-to-speech/"):
+		return "text_to_speech"
+	case strings.HasPrefix(path, "/v1/speech-to-text/realtime"):
+		return "speech_to_text.realtime"
+	case strings.HasPrefix(path, "/v1/speech-to-text"):
+		return "speech_to_text"
+	case strings.HasPrefix(path, "/v1/models"):
+		return "models"
+	default:
+		return "other"
+	}
+}
+
+// redactURL hides credentials passed in the query string.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	query := redacted.Query()
+	for _, key := range []string{"token", "xi_api_key", "xi-api-key"} {
+		if query.Has(key) {
+			query.Set(key, "REDACTED")
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
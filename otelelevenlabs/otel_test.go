@@ -0,0 +1,197 @@
+package otelelevenlabs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestInstrumentation(t *testing.T) (*Instrumentation, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	inst, err := New(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inst, spans, reader
+}
+
+func attrValue(attrs []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func counterSum(t *testing.T, reader *sdkmetric.ManualReader, name string) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				total += point.Value
+			}
+		}
+	}
+	return total
+}
+
+func TestHTTPSpanHasRequestIDAndCharacterMetric(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "req_1")
+		w.Header().Set("x-character-count", "12")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	inst, spans, reader := newTestInstrumentation(t)
+	cfg := tts.DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	client := tts.NewClientWithConfig(inst.TTSConfig(cfg))
+	if _, err := client.Synthesize(context.Background(), tts.SynthesisRequest{VoiceID: "voice_123", Text: "Hello there."}); err != nil {
+		t.Fatal(err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(ended))
+	}
+	span := ended[0]
+	if got, want := span.Name(), "elevenlabs text_to_speech"; got != want {
+		t.Errorf("span name = %q, want %q", got, want)
+	}
+	if value, _ := attrValue(span.Attributes(), AttrRequestID); value.AsString() != "req_1" {
+		t.Errorf("request id = %q, want req_1", value.AsString())
+	}
+	if value, _ := attrValue(span.Attributes(), "http.response.status_code"); value.AsInt64() != http.StatusOK {
+		t.Errorf("status code = %d, want 200", value.AsInt64())
+	}
+	if got, want := counterSum(t, reader, "elevenlabs.characters"), int64(12); got != want {
+		t.Errorf("elevenlabs.characters = %d, want %d", got, want)
+	}
+}
+
+func TestWebSocketSpanCountsEventsAndRecordsErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		ctx := r.Context()
+		if _, _, err = conn.Read(ctx); err != nil {
+			return
+		}
+		for _, msg := range []string{`{"audio":"YWJj"}`, `{"error":"quota exceeded"}`, `{"isFinal":true}`} {
+			_ = conn.Write(ctx, websocket.MessageText, []byte(msg))
+		}
+	}))
+	defer server.Close()
+
+	inst, spans, reader := newTestInstrumentation(t)
+	var dials atomic.Int32
+	cfg := tts.DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	conn, err := tts.NewClientWithConfig(inst.TTSConfig(cfg)).ConnectRealtime(context.Background(),
+		tts.StreamInputRequest{VoiceID: "voice_123"}, tts.WithDialer(countingDialer{dials: &dials}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dials.Load(), int32(1); got != want {
+		t.Errorf("dials through the caller's dialer = %d, want %d", got, want)
+	}
+	streamer := tts.NewRealtimeSynthesizer(context.Background(), conn)
+	streamer.Start()
+	if err = streamer.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = streamer.Close()
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(ended))
+	}
+	span := ended[0]
+	if got, want := span.Name(), "elevenlabs text_to_speech.realtime"; got != want {
+		t.Errorf("span name = %q, want %q", got, want)
+	}
+	for key, want := range map[attribute.Key]int64{
+		"elevenlabs.websocket.messages_sent":     1,
+		"elevenlabs.websocket.messages_received": 3,
+		"elevenlabs.websocket.events.audio":      1,
+		"elevenlabs.websocket.events.error":      1,
+	} {
+		if value, _ := attrValue(span.Attributes(), key); value.AsInt64() != want {
+			t.Errorf("%s = %d, want %d", key, value.AsInt64(), want)
+		}
+	}
+	var errorEvents int
+	for _, event := range span.Events() {
+		if event.Name == "elevenlabs.error" {
+			errorEvents++
+		}
+	}
+	if errorEvents != 1 {
+		t.Errorf("error span events = %d, want 1", errorEvents)
+	}
+	if got, want := counterSum(t, reader, "elevenlabs.websocket.events"), int64(3); got != want {
+		t.Errorf("elevenlabs.websocket.events = %d, want %d", got, want)
+	}
+}
+
+type countingDialer struct {
+	dials *atomic.Int32
+}
+
+func (d countingDialer) Dial(ctx context.Context, url string, header http.Header) (transcripts.WebSocketConn, error) {
+	d.dials.Add(1)
+	return transcripts.DefaultDialer().Dial(ctx, url, header)
+}
+
+func TestOperationNamesEndpoints(t *testing.T) {
+	t.Parallel()
+
+	for path, want := range map[string]string{
+		"/v1/text-to-speech/voice_123":                    "text_to_speech",
+		"/v1/text-to-speech/voice_123/stream":             "text_to_speech.stream",
+		"/v1/text-to-speech/voice_123/stream-input":       "text_to_speech.realtime",
+		"/v1/text-to-speech/voice_123/multi-stream-input": "text_to_speech.realtime_multi_context",
+		"/v1/speech-to-text/realtime":                     "speech_to_text.realtime",
+		"/v1/speech-to-text":                              "speech_to_text",
+		"/v1/models":                                      "models",
+	} {
+		if got := operation(path); got != want {
+			t.Errorf("operation(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
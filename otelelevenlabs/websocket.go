@@ -0,0 +1,175 @@
+package otelelevenlabs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebSocketMiddleware returns a middleware whose connections are traced. The span starts with
+// the dial and ends when the connection is closed or a read fails permanently.
+func (i *Instrumentation) WebSocketMiddleware() transcripts.WebSocketMiddleware {
+	return transcripts.WebSocketMiddleware{Dial: i.dial}
+}
+
+// Dialer returns a transcripts.WebSocketDialer that runs base through WebSocketMiddleware.
+// A nil base means transcripts.DefaultDialer().
+func (i *Instrumentation) Dialer(base transcripts.WebSocketDialer) transcripts.WebSocketDialer {
+	return transcripts.ChainDialer(base, i.WebSocketMiddleware())
+}
+
+func (i *Instrumentation) dial(ctx context.Context, rawURL string, header http.Header, next transcripts.DialFunc) (transcripts.WebSocketConn, error) {
+	op, full := "other", rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		op, full = operation(u.Path), redactURL(u)
+	}
+	ctx, span := i.tracer.Start(ctx, "elevenlabs "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrOperation.String(op), attribute.String("url.full", full)))
+	conn, err := next(ctx, rawURL, header)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return nil, err
+	}
+	if resp := conn.Response(); resp != nil {
+		if requestID := resp.Header.Get("request-id"); requestID != "" {
+			span.SetAttributes(AttrRequestID.String(requestID))
+		}
+	}
+	span.AddEvent("connected")
+	return &tracedConn{
+		WebSocketConn: conn,
+		ctx:           ctx,
+		span:          span,
+		inst:          i,
+		op:            op,
+		received:      make(map[string]int),
+	}, nil
+}
+
+type tracedConn struct {
+	transcripts.WebSocketConn
+	ctx  context.Context
+	span trace.Span
+	inst *Instrumentation
+	op   string
+
+	mu       sync.Mutex
+	sent     int
+	received map[string]int
+	ended    bool
+}
+
+func (c *tracedConn) ReadMessage(ctx context.Context) (transcripts.MessageType, []byte, error) {
+	messageType, data, err := c.WebSocketConn.ReadMessage(ctx)
+	if err != nil {
+		var permanent *transcripts.PermanentError
+		if errors.As(err, &permanent) {
+			if status := websocket.CloseStatus(permanent.Err); status != websocket.StatusNormalClosure && !c.isEnded() {
+				c.span.RecordError(permanent.Err)
+				c.span.SetStatus(codes.Error, permanent.Err.Error())
+			}
+			c.end()
+		}
+		return messageType, data, err
+	}
+	if messageType == transcripts.MessageText {
+		c.observe(data)
+	}
+	return messageType, data, nil
+}
+
+func (c *tracedConn) WriteMessage(ctx context.Context, messageType transcripts.MessageType, data []byte) error {
+	err := c.WebSocketConn.WriteMessage(ctx, messageType, data)
+	if err == nil {
+		c.mu.Lock()
+		c.sent++
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *tracedConn) Close() error {
+	err := c.WebSocketConn.Close()
+	c.end()
+	return err
+}
+
+// observe counts one server message and records error events.
+func (c *tracedConn) observe(data []byte) {
+	var probe struct {
+		MessageType string `json:"message_type"`
+		Error       string `json:"error"`
+		Message     string `json:"message"`
+		Audio       string `json:"audio"`
+		IsFinal     bool   `json:"isFinal"`
+	}
+	_ = json.Unmarshal(data, &probe)
+	eventType := "unknown"
+	switch {
+	case probe.MessageType != "":
+		eventType = probe.MessageType
+	case probe.Error != "":
+		eventType = "error"
+	case probe.Audio != "":
+		eventType = "audio"
+	case probe.IsFinal:
+		eventType = "final"
+	}
+
+	c.mu.Lock()
+	c.received[eventType]++
+	c.mu.Unlock()
+	c.inst.events.Add(c.ctx, 1, metric.WithAttributes(AttrOperation.String(c.op), AttrEventType.String(eventType)))
+	if probe.Error != "" {
+		c.span.AddEvent("elevenlabs.error", trace.WithAttributes(
+			AttrEventType.String(eventType),
+			attribute.String("error.message", probe.Error),
+		))
+	}
+}
+
+func (c *tracedConn) isEnded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ended
+}
+
+// end sets the message counts and ends the span once.
+func (c *tracedConn) end() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ended {
+		return
+	}
+	c.ended = true
+	total := 0
+	types := make([]string, 0, len(c.received))
+	for eventType, count := range c.received {
+		types = append(types, eventType)
+		total += count
+	}
+	sort.Strings(types)
+	attrs := []attribute.KeyValue{
+		attribute.Int("elevenlabs.websocket.messages_sent", c.sent),
+		attribute.Int("elevenlabs.websocket.messages_received", total),
+	}
+	for _, eventType := range types {
+		attrs = append(attrs, attribute.Int("elevenlabs.websocket.events."+eventType, c.received[eventType]))
+	}
+	c.span.SetAttributes(attrs...)
+	c.span.End()
+}
@@ -0,0 +1,123 @@
+package subtitles
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxLineLength is the default maximum number of characters per line.
+	DefaultMaxLineLength = 42
+	// DefaultMaxLines is the default maximum number of lines per cue.
+	DefaultMaxLines = 2
+	// DefaultMaxCueDuration is the default maximum duration of a single cue.
+	DefaultMaxCueDuration = 7 * time.Second
+)
+
+// Word is a single timed word used to build subtitle cues.
+type Word struct {
+	Text    string
+	Start   time.Duration
+	End     time.Duration
+	Speaker string
+}
+
+// Cue is one subtitle entry with its display interval and wrapped lines.
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Speaker string
+	Lines   []string
+}
+
+// Options controls how words are grouped into cues.
+type Options struct {
+	// MaxLineLength is the maximum number of characters per line. Defaults to DefaultMaxLineLength.
+	MaxLineLength int
+	// MaxLines is the maximum number of lines per cue. Defaults to DefaultMaxLines.
+	MaxLines int
+	// MaxCueDuration is the maximum duration of a cue. Defaults to DefaultMaxCueDuration.
+	MaxCueDuration time.Duration
+	// SpeakerLabels prefixes each cue with its speaker when the speaker is known.
+	SpeakerLabels bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxLineLength <= 0 {
+		o.MaxLineLength = DefaultMaxLineLength
+	}
+	if o.MaxLines <= 0 {
+		o.MaxLines = DefaultMaxLines
+	}
+	if o.MaxCueDuration <= 0 {
+		o.MaxCueDuration = DefaultMaxCueDuration
+	}
+	return o
+}
+
+// BuildCues groups timed words into cues.
+// A new cue starts when the speaker changes, when the next word would not fit into
+// MaxLines lines of MaxLineLength characters, or when the cue would exceed MaxCueDuration.
+// A single word longer than MaxLineLength is kept on its own line.
+func BuildCues(words []Word, opts Options) []Cue {
+	opts = opts.withDefaults()
+
+	var (
+		cues    []Cue
+		current *Cue
+	)
+	flush := func() {
+		if current != nil && len(current.Lines) > 0 {
+			cues = append(cues, *current)
+		}
+		current = nil
+	}
+
+	for _, word := range words {
+		text := strings.TrimSpace(word.Text)
+		if text == "" {
+			continue
+		}
+		if current != nil {
+			switch {
+			case word.Speaker != current.Speaker:
+				flush()
+			case word.End-current.Start > opts.MaxCueDuration:
+				flush()
+			case !fits(current.Lines, text, opts):
+				flush()
+			}
+		}
+		if current == nil {
+			current = &Cue{
+				Start:   word.Start,
+				End:     word.End,
+				Speaker: word.Speaker,
+			}
+		}
+		current.Lines = appendWord(current.Lines, text, opts.MaxLineLength)
+		if word.End > current.End {
+			current.End = word.End
+		}
+	}
+	flush()
+	return cues
+}
+
+func fits(lines []string, text string, opts Options) bool {
+	return len(appendWord(lines, text, opts.MaxLineLength)) <= opts.MaxLines
+}
+
+func appendWord(lines []string, text string, maxLineLength int) []string {
+	if len(lines) == 0 {
+		return []string{text}
+	}
+	last := lines[len(lines)-1]
+	if utf8.RuneCountInString(last)+1+utf8.RuneCountInString(text) <= maxLineLength {
+		out := append([]string(nil), lines...)
+		out[len(out)-1] = last + " " + text
+		return out
+	}
+	return append(append([]string(nil), lines...), text)
+}
@@ -0,0 +1,141 @@
+package subtitles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WriteSRT writes cues in SubRip format.
+func WriteSRT(w io.Writer, cues []Cue, opts Options) error {
+	bw := bufio.NewWriter(w)
+	for i, cue := range cues {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "%d\n%s --> %s\n", i+1, formatTimestamp(cue.Start, ','), formatTimestamp(cue.End, ','))
+		for j, line := range cue.Lines {
+			if j == 0 && opts.SpeakerLabels && cue.Speaker != "" {
+				line = "[" + cue.Speaker + "] " + line
+			}
+			bw.WriteString(line)
+			bw.WriteString("\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteWebVTT writes cues in WebVTT format.
+// Speakers are written as voice spans when SpeakerLabels is set.
+func WriteWebVTT(w io.Writer, cues []Cue, opts Options) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n")
+	for i, cue := range cues {
+		fmt.Fprintf(bw, "\n%d\n%s --> %s\n", i+1, formatTimestamp(cue.Start, '.'), formatTimestamp(cue.End, '.'))
+		text := vttEscaper.Replace(strings.Join(cue.Lines, "\n"))
+		if opts.SpeakerLabels && cue.Speaker != "" {
+			text = "<v " + vttEscaper.Replace(cue.Speaker) + ">" + text
+		}
+		bw.WriteString(text)
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// WriteTTML writes cues as a minimal TTML document.
+// Speakers are declared as ttm:agent elements in the head and referenced from each paragraph.
+// Agent IDs are the speaker IDs with characters not allowed in an xml:id replaced by underscores.
+// When SpeakerLabels is set, speakers are also written as a text prefix.
+func WriteTTML(w io.Writer, cues []Cue, opts Options) error {
+	speakers, agents := ttmlAgents(cues)
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	bw.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttm="http://www.w3.org/ns/ttml#metadata">` + "\n")
+	if len(speakers) > 0 {
+		bw.WriteString("<head>\n<metadata>\n")
+		for _, speaker := range speakers {
+			fmt.Fprintf(bw, `<ttm:agent xml:id="%s" type="person"><ttm:name type="full">%s</ttm:name></ttm:agent>`+"\n",
+				agents[speaker], xmlEscaper.Replace(speaker))
+		}
+		bw.WriteString("</metadata>\n</head>\n")
+	}
+	bw.WriteString("<body>\n<div>\n")
+	for _, cue := range cues {
+		fmt.Fprintf(bw, `<p begin="%s" end="%s"`, formatTimestamp(cue.Start, '.'), formatTimestamp(cue.End, '.'))
+		if cue.Speaker != "" {
+			fmt.Fprintf(bw, ` ttm:agent="%s"`, agents[cue.Speaker])
+		}
+		bw.WriteString(">")
+		for j, line := range cue.Lines {
+			if j > 0 {
+				bw.WriteString("<br/>")
+			}
+			if j == 0 && opts.SpeakerLabels && cue.Speaker != "" {
+				line = "[" + cue.Speaker + "] " + line
+			}
+			bw.WriteString(xmlEscaper.Replace(line))
+		}
+		bw.WriteString("</p>\n")
+	}
+	bw.WriteString("</div>\n</body>\n</tt>\n")
+	return bw.Flush()
+}
+
+// ttmlAgents returns the speakers of cues in order of appearance and a unique xml:id for each.
+func ttmlAgents(cues []Cue) ([]string, map[string]string) {
+	var speakers []string
+	agents := map[string]string{}
+	used := map[string]bool{}
+	for _, cue := range cues {
+		if cue.Speaker == "" {
+			continue
+		}
+		if _, ok := agents[cue.Speaker]; ok {
+			continue
+		}
+		base := xmlID(cue.Speaker)
+		id := base
+		for n := 2; used[id]; n++ {
+			id = base + "_" + strconv.Itoa(n)
+		}
+		used[id] = true
+		agents[cue.Speaker] = id
+		speakers = append(speakers, cue.Speaker)
+	}
+	return speakers, agents
+}
+
+// xmlID turns s into an XML name without colons: it starts with a letter or underscore and contains
+// letters, digits, '.', '-' and '_'.
+func xmlID(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '.' || r == '-' || unicode.IsDigit(r)):
+		case i == 0 && (r == '.' || r == '-' || unicode.IsDigit(r)):
+			b.WriteByte('_')
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var (
+	vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+)
+
+func formatTimestamp(d time.Duration, fractionSeparator byte) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, fractionSeparator, ms%1000)
+}
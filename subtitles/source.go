@@ -0,0 +1,91 @@
+package subtitles
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+// FromTranscriptionWords converts words returned by transcripts.Client.Transcribe.
+// Spacing entries are dropped.
+func FromTranscriptionWords(words []transcripts.TranscriptionWord) []Word {
+	out := make([]Word, 0, len(words))
+	for _, word := range words {
+		if word.Type == "spacing" {
+			continue
+		}
+		out = append(out, Word{
+			Text:    word.Text,
+			Start:   seconds(word.Start),
+			End:     seconds(word.End),
+			Speaker: word.SpeakerID,
+		})
+	}
+	return out
+}
+
+// FromRealtimeWords converts words from a committed realtime transcript with timestamps.
+// Spacing entries are dropped.
+func FromRealtimeWords(words []transcripts.RealtimeTranscriptWord) []Word {
+	out := make([]Word, 0, len(words))
+	for _, word := range words {
+		if word.Type == "spacing" {
+			continue
+		}
+		out = append(out, Word{
+			Text:    word.Text,
+			Start:   seconds(word.Start),
+			End:     seconds(word.End),
+			Speaker: word.SpeakerID,
+		})
+	}
+	return out
+}
+
+// FromAlignment converts character-level TTS alignment into words split at whitespace.
+// The offset is added to every timestamp, which lets callers place successive
+// audio chunks on a single timeline.
+func FromAlignment(alignment tts.Alignment, offset time.Duration) []Word {
+	var (
+		out     []Word
+		builder strings.Builder
+		start   time.Duration
+		end     time.Duration
+	)
+	flush := func() {
+		if builder.Len() > 0 {
+			out = append(out, Word{Text: builder.String(), Start: start, End: end})
+			builder.Reset()
+		}
+	}
+
+	for i, char := range alignment.Chars {
+		if i >= len(alignment.CharStartTimesMs) {
+			break
+		}
+		charStart := offset + time.Duration(alignment.CharStartTimesMs[i])*time.Millisecond
+		charEnd := charStart
+		if i < len(alignment.CharDurationsMs) {
+			charEnd += time.Duration(alignment.CharDurationsMs[i]) * time.Millisecond
+		}
+		if strings.TrimFunc(char, unicode.IsSpace) == "" {
+			flush()
+			continue
+		}
+		if builder.Len() == 0 {
+			start = charStart
+		}
+		builder.WriteString(char)
+		end = charEnd
+	}
+	flush()
+	return out
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(math.Round(value * float64(time.Second)))
+}
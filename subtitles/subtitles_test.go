@@ -0,0 +1,131 @@
+package subtitles
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+	"github.com/gouyuwang/go-elevenlabs/tts"
+)
+
+func TestBuildCuesWrapsAndSplitsBySpeakerAndDuration(t *testing.T) {
+	t.Parallel()
+
+	words := FromRealtimeWords([]transcripts.RealtimeTranscriptWord{
+		{Text: "hello", Start: 0, End: 0.4, Type: "word", SpeakerID: "speaker_0"},
+		{Text: " ", Start: 0.4, End: 0.5, Type: "spacing", SpeakerID: "speaker_0"},
+		{Text: "there", Start: 0.5, End: 0.9, Type: "word", SpeakerID: "speaker_0"},
+		{Text: "general", Start: 1.0, End: 1.4, Type: "word", SpeakerID: "speaker_0"},
+		{Text: "kenobi", Start: 1.5, End: 2.0, Type: "word", SpeakerID: "speaker_1"},
+		{Text: "later", Start: 9.0, End: 9.5, Type: "word", SpeakerID: "speaker_1"},
+	})
+
+	cues := BuildCues(words, Options{MaxLineLength: 12, MaxLines: 1, MaxCueDuration: 5 * time.Second})
+	if got, want := len(cues), 4; got != want {
+		t.Fatalf("len(cues) = %d, want %d: %+v", got, want, cues)
+	}
+	if got, want := cues[0].Lines[0], "hello there"; got != want {
+		t.Fatalf("cues[0].Lines[0] = %q, want %q", got, want)
+	}
+	if got, want := cues[1].Lines[0], "general"; got != want {
+		t.Fatalf("cues[1].Lines[0] = %q, want %q", got, want)
+	}
+	if got, want := cues[2].Speaker, "speaker_1"; got != want {
+		t.Fatalf("cues[2].Speaker = %q, want %q", got, want)
+	}
+	if got, want := cues[3].Start, 9*time.Second; got != want {
+		t.Fatalf("cues[3].Start = %v, want %v", got, want)
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	t.Parallel()
+
+	cues := BuildCues(FromTranscriptionWords([]transcripts.TranscriptionWord{
+		{Text: "Fish", Start: 0.3, End: 0.6, SpeakerID: "A"},
+		{Text: "&", Start: 0.7, End: 0.8, SpeakerID: "A"},
+		{Text: "chips", Start: 0.9, End: 1.25, SpeakerID: "A"},
+	}), Options{})
+	opts := Options{SpeakerLabels: true}
+
+	var srt bytes.Buffer
+	if err := WriteSRT(&srt, cues, opts); err != nil {
+		t.Fatalf("WriteSRT() error = %v", err)
+	}
+	if got, want := srt.String(), "1\n00:00:00,300 --> 00:00:01,250\n[A] Fish & chips\n"; got != want {
+		t.Fatalf("srt = %q, want %q", got, want)
+	}
+
+	var vtt bytes.Buffer
+	if err := WriteWebVTT(&vtt, cues, opts); err != nil {
+		t.Fatalf("WriteWebVTT() error = %v", err)
+	}
+	if got, want := vtt.String(), "WEBVTT\n\n1\n00:00:00.300 --> 00:00:01.250\n<v A>Fish &amp; chips\n"; got != want {
+		t.Fatalf("vtt = %q, want %q", got, want)
+	}
+
+	var ttml bytes.Buffer
+	if err := WriteTTML(&ttml, cues, Options{}); err != nil {
+		t.Fatalf("WriteTTML() error = %v", err)
+	}
+	for _, want := range []string{
+		`<ttm:agent xml:id="A" type="person"><ttm:name type="full">A</ttm:name></ttm:agent>`,
+		`<p begin="00:00:00.300" end="00:00:01.250" ttm:agent="A">Fish &amp; chips</p>`,
+	} {
+		if !strings.Contains(ttml.String(), want) {
+			t.Fatalf("ttml = %q, want it to contain %q", ttml.String(), want)
+		}
+	}
+}
+
+func TestWriteTTMLDeclaresXMLSafeAgents(t *testing.T) {
+	t.Parallel()
+
+	cues := []Cue{
+		{Start: 0, End: time.Second, Speaker: "1 Ann", Lines: []string{"Hi"}},
+		{Start: time.Second, End: 2 * time.Second, Speaker: "1:Ann", Lines: []string{"Hello"}},
+		{Start: 2 * time.Second, End: 3 * time.Second, Speaker: "1 Ann", Lines: []string{"Bye"}},
+	}
+	var ttml bytes.Buffer
+	if err := WriteTTML(&ttml, cues, Options{}); err != nil {
+		t.Fatalf("WriteTTML() error = %v", err)
+	}
+	got := ttml.String()
+	for _, want := range []string{
+		"<head>\n<metadata>\n" +
+			`<ttm:agent xml:id="_1_Ann" type="person"><ttm:name type="full">1 Ann</ttm:name></ttm:agent>` + "\n" +
+			`<ttm:agent xml:id="_1_Ann_2" type="person"><ttm:name type="full">1:Ann</ttm:name></ttm:agent>` + "\n" +
+			"</metadata>\n</head>\n",
+		`<p begin="00:00:01.000" end="00:00:02.000" ttm:agent="_1_Ann_2">Hello</p>`,
+		`<p begin="00:00:02.000" end="00:00:03.000" ttm:agent="_1_Ann">Bye</p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("ttml = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestFromAlignmentGroupsCharactersIntoWords(t *testing.T) {
+	t.Parallel()
+
+	words := FromAlignment(tts.Alignment{
+		Chars:            []string{"H", "i", " ", "y", "o", "u"},
+		CharStartTimesMs: []int{0, 100, 200, 300, 400, 500},
+		CharDurationsMs:  []int{100, 100, 100, 100, 100, 100},
+	}, time.Second)
+
+	if got, want := len(words), 2; got != want {
+		t.Fatalf("len(words) = %d, want %d", got, want)
+	}
+	if got, want := words[1].Text, "you"; got != want {
+		t.Fatalf("words[1].Text = %q, want %q", got, want)
+	}
+	if got, want := words[1].Start, 1300*time.Millisecond; got != want {
+		t.Fatalf("words[1].Start = %v, want %v", got, want)
+	}
+	if got, want := words[1].End, 1600*time.Millisecond; got != want {
+		t.Fatalf("words[1].End = %v, want %v", got, want)
+	}
+}
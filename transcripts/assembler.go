@@ -0,0 +1,196 @@
+package transcripts
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TranscriptSegment is one committed piece of a live transcript.
+type TranscriptSegment struct {
+	Text     string
+	Language string
+	// Words is only populated when the session includes timestamps.
+	Words []RealtimeTranscriptWord
+	// Revision is the assembler revision at which the segment was committed.
+	Revision uint64
+}
+
+// TranscriptSnapshot is a consistent copy of an Assembler's state.
+type TranscriptSnapshot struct {
+	Revision  uint64
+	Committed string
+	Partial   string
+	Segments  []TranscriptSegment
+}
+
+// Text returns the committed transcript followed by the current partial.
+func (s TranscriptSnapshot) Text() string {
+	return joinTranscript(s.Committed, s.Partial)
+}
+
+// Words returns the word timings of all committed segments.
+func (s TranscriptSnapshot) Words() []RealtimeTranscriptWord {
+	var words []RealtimeTranscriptWord
+	for _, segment := range s.Segments {
+		words = append(words, segment.Words...)
+	}
+	return words
+}
+
+// TranscriptDiff describes what changed after a given revision.
+type TranscriptDiff struct {
+	Revision uint64
+	// Segments are the segments committed after the requested revision.
+	Segments []TranscriptSegment
+	// Partial is the current partial transcript.
+	Partial string
+}
+
+// Assembler keeps a live transcript from recognizer events.
+// Partial transcripts replace each other and committed transcripts are appended.
+// When a session sends both committed_transcript and committed_transcript_with_timestamps
+// for the same commit, they are merged into one segment.
+//
+// Pass Handle to NewRecognizer. All methods are safe for concurrent use.
+type Assembler struct {
+	mu        sync.RWMutex
+	revision  uint64
+	committed strings.Builder
+	partial   string
+	segments  []TranscriptSegment
+	// pending is set when the last segment still expects its counterpart event.
+	pending       bool
+	pendingHasTS  bool
+	onChangeHooks []func(TranscriptDiff)
+}
+
+// NewAssembler creates an empty Assembler.
+func NewAssembler() *Assembler {
+	return &Assembler{}
+}
+
+// OnChange registers fn to be called after every change with the change itself.
+// Hooks run on the goroutine that delivers events and must not block.
+func (a *Assembler) OnChange(fn func(TranscriptDiff)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onChangeHooks = append(a.onChangeHooks, fn)
+}
+
+// Handle is a ServerEventHandler that feeds the assembler.
+func (a *Assembler) Handle(_ context.Context, event ServerEvent) {
+	a.mu.Lock()
+	var diff TranscriptDiff
+	changed := false
+	switch e := event.(type) {
+	case SpeechRecognizingEventArgs:
+		if e.Text != a.partial {
+			a.partial = e.Text
+			changed = true
+		}
+	case SpeechRecognizedEventArgs:
+		diff.Segments, changed = a.commit(TranscriptSegment{Text: e.Text}, false)
+	case SpeechRecognizedWithTimestampEventArgs:
+		diff.Segments, changed = a.commit(TranscriptSegment{Text: e.Text, Language: e.Language, Words: e.Words}, true)
+	}
+	if !changed {
+		a.mu.Unlock()
+		return
+	}
+	a.revision++
+	for i := range diff.Segments {
+		diff.Segments[i].Revision = a.revision
+	}
+	if len(diff.Segments) > 0 {
+		// The changed segment is always the last one, which keeps revisions sorted.
+		a.segments[len(a.segments)-1].Revision = a.revision
+	}
+	diff.Revision = a.revision
+	diff.Partial = a.partial
+	hooks := a.onChangeHooks
+	a.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(diff)
+	}
+}
+
+// commit must be called with a.mu held.
+func (a *Assembler) commit(segment TranscriptSegment, hasTimestamps bool) ([]TranscriptSegment, bool) {
+	a.partial = ""
+	if a.pending && a.pendingHasTS != hasTimestamps {
+		last := &a.segments[len(a.segments)-1]
+		if last.Text == segment.Text {
+			a.pending = false
+			if hasTimestamps {
+				last.Words = segment.Words
+				last.Language = segment.Language
+			}
+			return []TranscriptSegment{cloneSegment(*last)}, true
+		}
+	}
+	a.segments = append(a.segments, segment)
+	a.pending = true
+	a.pendingHasTS = hasTimestamps
+	if text := strings.TrimSpace(segment.Text); text != "" {
+		if a.committed.Len() > 0 {
+			a.committed.WriteString(" ")
+		}
+		a.committed.WriteString(text)
+	}
+	return []TranscriptSegment{cloneSegment(segment)}, true
+}
+
+// Snapshot returns a copy of the current state.
+func (a *Assembler) Snapshot() TranscriptSnapshot {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	segments := make([]TranscriptSegment, len(a.segments))
+	for i, segment := range a.segments {
+		segments[i] = cloneSegment(segment)
+	}
+	return TranscriptSnapshot{
+		Revision:  a.revision,
+		Committed: a.committed.String(),
+		Partial:   a.partial,
+		Segments:  segments,
+	}
+}
+
+// Since returns the segments committed after revision together with the current partial.
+// Callers keep the returned Revision and pass it to the next call, so each reader has its own cursor.
+// A segment that later gains word timings is reported again with its new revision.
+func (a *Assembler) Since(revision uint64) TranscriptDiff {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	diff := TranscriptDiff{
+		Revision: a.revision,
+		Partial:  a.partial,
+	}
+	start := sort.Search(len(a.segments), func(i int) bool {
+		return a.segments[i].Revision > revision
+	})
+	for _, segment := range a.segments[start:] {
+		diff.Segments = append(diff.Segments, cloneSegment(segment))
+	}
+	return diff
+}
+
+func cloneSegment(segment TranscriptSegment) TranscriptSegment {
+	segment.Words = append([]RealtimeTranscriptWord(nil), segment.Words...)
+	return segment
+}
+
+func joinTranscript(committed, partial string) string {
+	partial = strings.TrimSpace(partial)
+	switch {
+	case committed == "":
+		return partial
+	case partial == "":
+		return committed
+	default:
+		return committed + " " + partial
+	}
+}
@@ -0,0 +1,71 @@
+package transcripts
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestAssemblerMergesPartialsAndCommits(t *testing.T) {
+	t.Parallel()
+
+	assembler := NewAssembler()
+	var (
+		mu      sync.Mutex
+		changes []TranscriptDiff
+	)
+	assembler.OnChange(func(diff TranscriptDiff) {
+		mu.Lock()
+		changes = append(changes, diff)
+		mu.Unlock()
+	})
+
+	ctx := context.Background()
+	assembler.Handle(ctx, SpeechRecognizingEventArgs{Text: "hel"})
+	assembler.Handle(ctx, SpeechRecognizingEventArgs{Text: "hello"})
+	if got, want := assembler.Snapshot().Text(), "hello"; got != want {
+		t.Fatalf("Text() = %q, want %q", got, want)
+	}
+
+	assembler.Handle(ctx, SpeechRecognizedEventArgs{Text: "hello there"})
+	assembler.Handle(ctx, SpeechRecognizedWithTimestampEventArgs{
+		Text:  "hello there",
+		Words: []RealtimeTranscriptWord{{Text: "hello"}, {Text: " ", Type: "spacing"}, {Text: "there"}},
+	})
+	first := assembler.Since(0)
+	if got, want := len(first.Segments), 1; got != want {
+		t.Fatalf("len(Segments) = %d, want %d", got, want)
+	}
+	if got, want := len(first.Segments[0].Words), 3; got != want {
+		t.Fatalf("len(Words) = %d, want %d", got, want)
+	}
+
+	assembler.Handle(ctx, SpeechRecognizingEventArgs{Text: "general"})
+	assembler.Handle(ctx, SpeechRecognizedEventArgs{Text: "general kenobi"})
+	assembler.Handle(ctx, SpeechRecognizingEventArgs{Text: "you are"})
+
+	diff := assembler.Since(first.Revision)
+	if got, want := len(diff.Segments), 1; got != want {
+		t.Fatalf("len(diff.Segments) = %d, want %d", got, want)
+	}
+	if got, want := diff.Segments[0].Text, "general kenobi"; got != want {
+		t.Fatalf("diff.Segments[0].Text = %q, want %q", got, want)
+	}
+	if got, want := diff.Partial, "you are"; got != want {
+		t.Fatalf("diff.Partial = %q, want %q", got, want)
+	}
+
+	snapshot := assembler.Snapshot()
+	if got, want := snapshot.Committed, "hello there general kenobi"; got != want {
+		t.Fatalf("Committed = %q, want %q", got, want)
+	}
+	if got, want := snapshot.Text(), "hello there general kenobi you are"; got != want {
+		t.Fatalf("Text() = %q, want %q", got, want)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := len(changes), 7; got != want {
+		t.Fatalf("len(changes) = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,254 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultAudioChunkDuration is the default amount of audio sent per chunk.
+	DefaultAudioChunkDuration = 100 * time.Millisecond
+	// DefaultMaxAudioChunkDuration is the default upper bound for a single chunk.
+	DefaultMaxAudioChunkDuration = time.Second
+	// minAudioChunkDuration is the floor used when shrinking chunks after chunk_size_exceeded.
+	minAudioChunkDuration = 10 * time.Millisecond
+	// resendWindow is how long sent chunks are kept in case the server rejects them.
+	resendWindow = 5 * time.Second
+)
+
+// ErrAudioWriterClosed is returned when writing to a closed AudioWriter.
+var ErrAudioWriterClosed = errors.New("audio writer closed")
+
+// AudioWriterOptions configures an AudioWriter.
+type AudioWriterOptions struct {
+	// ChunkDuration is the amount of audio sent per chunk. Defaults to DefaultAudioChunkDuration.
+	ChunkDuration time.Duration
+	// MaxChunkDuration caps ChunkDuration. Defaults to DefaultMaxAudioChunkDuration.
+	MaxChunkDuration time.Duration
+	// Realtime paces chunks to the audio clock instead of sending them as fast as possible.
+	Realtime bool
+}
+
+// AudioWriter splits an audio byte stream into frame-aligned chunks and sends them to a Recognizer.
+// It implements io.WriteCloser and io.ReaderFrom. Close, and ReadFrom on EOF,
+// send any buffered audio and commit. A trailing partial frame is padded with zeros.
+//
+// When the server reports chunk_size_exceeded, the writer halves its chunk size and, before sending
+// more audio, re-sends the rejected chunks in the smaller size. Every chunk of the rejected size sent
+// in the last five seconds counts as rejected, since the server rejects them all. Rejections that
+// arrive after Close are not re-sent.
+// An AudioWriter is not safe for concurrent use.
+type AudioWriter struct {
+	recognizer *Recognizer
+	opts       AudioWriterOptions
+	frameSize  int
+	chunkSize  atomic.Int64
+	buf        []byte
+	started    time.Time
+	sent       time.Duration
+	closed     bool
+	// history holds the chunks sent within resendWindow.
+	history []sentChunk
+
+	mu sync.Mutex
+	// rejected is set by chunk_size_exceeded until the writer re-sent the rejected chunks.
+	rejected bool
+	// ignore counts the chunk_size_exceeded events still expected for chunks already re-sent.
+	ignore int
+}
+
+type sentChunk struct {
+	data []byte
+	at   time.Time
+}
+
+// AudioWriter returns a writer that streams audio to the recognizer.
+// It must be called before Start so that it can react to chunk_size_exceeded errors.
+func (r *Recognizer) AudioWriter(opts AudioWriterOptions) *AudioWriter {
+	if opts.MaxChunkDuration <= 0 {
+		opts.MaxChunkDuration = DefaultMaxAudioChunkDuration
+	}
+	if opts.ChunkDuration <= 0 {
+		opts.ChunkDuration = DefaultAudioChunkDuration
+	}
+	if opts.ChunkDuration > opts.MaxChunkDuration {
+		opts.ChunkDuration = opts.MaxChunkDuration
+	}
+	w := &AudioWriter{
+		recognizer: r,
+		opts:       opts,
+		frameSize:  bytesPerSampleForAudioFormat(r.conn.audioFormat),
+	}
+	w.chunkSize.Store(int64(w.bytesFor(opts.ChunkDuration)))
+	r.handlers = append(r.handlers, w.handle)
+	return w
+}
+
+// Write buffers p and sends every complete chunk.
+func (w *AudioWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrAudioWriterClosed
+	}
+	w.buf = append(w.buf, p...)
+	for {
+		w.requeueRejected()
+		size := int(w.chunkSize.Load())
+		if len(w.buf) < size {
+			return len(p), nil
+		}
+		if err := w.send(w.buf[:size]); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[size:]
+	}
+}
+
+// ReadFrom sends everything read from src and commits once src reaches EOF.
+func (w *AudioWriter) ReadFrom(src io.Reader) (int64, error) {
+	var total int64
+	buf := make([]byte, w.bytesFor(w.opts.MaxChunkDuration))
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			total += int64(n)
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return total, werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return total, w.Close()
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// Close sends the remaining audio, padding a partial frame with zeros, and commits.
+func (w *AudioWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.requeueRejected()
+	if partial := len(w.buf) % w.frameSize; partial > 0 {
+		w.buf = append(w.buf, make([]byte, w.frameSize-partial)...)
+	}
+	for len(w.buf) > 0 {
+		n := min(len(w.buf), int(w.chunkSize.Load()))
+		if err := w.send(w.buf[:n]); err != nil {
+			return err
+		}
+		w.buf = w.buf[n:]
+	}
+	w.buf = nil
+	w.history = nil
+	return w.recognizer.Commit()
+}
+
+// requeueRejected puts the chunks rejected with chunk_size_exceeded back in front of the buffer.
+func (w *AudioWriter) requeueRejected() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.rejected {
+		return
+	}
+	w.rejected = false
+	size := int(w.chunkSize.Load())
+	var audio []byte
+	rejected := 0
+	for _, chunk := range w.history {
+		if len(chunk.data) > size {
+			audio = append(audio, chunk.data...)
+			rejected++
+		}
+	}
+	w.history = nil
+	// The server reports every rejected chunk; the first report triggered this re-send.
+	w.ignore = max(rejected-1, 0)
+	w.buf = append(audio, w.buf...)
+	w.recognizer.log(slog.LevelWarn, "re-sending audio rejected with chunk_size_exceeded",
+		slog.Int("chunks", rejected), slog.Int("bytes", len(audio)))
+}
+
+func (w *AudioWriter) send(chunk []byte) error {
+	if w.opts.Realtime {
+		if w.started.IsZero() {
+			w.started = time.Now()
+		}
+		if err := sleepContext(w.recognizer.ctx, time.Until(w.started.Add(w.sent))); err != nil {
+			return err
+		}
+	}
+	if err := w.recognizer.Send(chunk); err != nil {
+		return err
+	}
+	w.sent += w.durationOf(len(chunk))
+	now := time.Now()
+	for len(w.history) > 0 && now.Sub(w.history[0].at) > resendWindow {
+		w.history = w.history[1:]
+	}
+	w.history = append(w.history, sentChunk{data: chunk, at: now})
+	return nil
+}
+
+func (w *AudioWriter) handle(_ context.Context, event ServerEvent) {
+	if event.ServerEventType() != ServerEventChunkSizeExceededError {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ignore > 0 {
+		w.ignore--
+		return
+	}
+	if w.rejected {
+		return
+	}
+	w.rejected = true
+	size := w.chunkSize.Load() / 2
+	size -= size % int64(w.frameSize)
+	if minSize := int64(w.bytesFor(minAudioChunkDuration)); size < minSize {
+		size = minSize
+	}
+	w.chunkSize.Store(size)
+	w.recognizer.log(slog.LevelWarn, "chunk size exceeded, reducing audio chunks", slog.Int64("chunk_bytes", size))
+}
+
+func (w *AudioWriter) bytesFor(d time.Duration) int {
+	frames := int(w.sampleRate() * int64(d) / int64(time.Second))
+	if frames < 1 {
+		frames = 1
+	}
+	return frames * w.frameSize
+}
+
+func (w *AudioWriter) durationOf(n int) time.Duration {
+	return time.Duration(int64(n/w.frameSize) * int64(time.Second) / w.sampleRate())
+}
+
+func (w *AudioWriter) sampleRate() int64 {
+	if w.recognizer.conn.sampleRate > 0 {
+		return w.recognizer.conn.sampleRate
+	}
+	return 16000
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,158 @@
+package transcripts
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAudioWriterSendsFrameAlignedChunksAndCommitsOnEOF(t *testing.T) {
+	t.Parallel()
+
+	wsConn := &captureWebSocketConn{}
+	recognizer := NewRecognizer(context.Background(), &Conn{
+		conn:        wsConn,
+		logger:      NopLogger{},
+		sampleRate:  16000,
+		audioFormat: AudioFormatPcm_16000,
+	})
+	writer := recognizer.AudioWriter(AudioWriterOptions{ChunkDuration: 10 * time.Millisecond})
+
+	n, err := writer.ReadFrom(bytes.NewReader(make([]byte, 1001)))
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if got, want := n, int64(1001); got != want {
+		t.Fatalf("ReadFrom() = %d, want %d", got, want)
+	}
+
+	var sizes []int
+	var commits int
+	for _, data := range wsConn.writes {
+		var payload InputAudioChunkEvent
+		if err = json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if payload.Commit {
+			commits++
+			continue
+		}
+		audio, err := base64.StdEncoding.DecodeString(payload.Audio)
+		if err != nil {
+			t.Fatalf("DecodeString() error = %v", err)
+		}
+		sizes = append(sizes, len(audio))
+	}
+	if got, want := len(sizes), 4; got != want {
+		t.Fatalf("len(chunks) = %d, want %d: %v", got, want, sizes)
+	}
+	for i, want := range []int{320, 320, 320, 42} {
+		if sizes[i] != want {
+			t.Fatalf("chunk[%d] = %d bytes, want %d", i, sizes[i], want)
+		}
+	}
+	if got, want := commits, 1; got != want {
+		t.Fatalf("commits = %d, want %d", got, want)
+	}
+	if _, err = writer.Write([]byte{0, 0}); err != ErrAudioWriterClosed {
+		t.Fatalf("Write() after close error = %v, want %v", err, ErrAudioWriterClosed)
+	}
+}
+
+func TestAudioWriterShrinksChunksOnChunkSizeExceeded(t *testing.T) {
+	t.Parallel()
+
+	recognizer := NewRecognizer(context.Background(), &Conn{
+		conn:        &captureWebSocketConn{},
+		logger:      NopLogger{},
+		sampleRate:  8000,
+		audioFormat: AudioFormatUlaw_8000,
+	})
+	writer := recognizer.AudioWriter(AudioWriterOptions{ChunkDuration: 500 * time.Millisecond})
+	if got, want := writer.chunkSize.Load(), int64(4000); got != want {
+		t.Fatalf("chunkSize = %d, want %d", got, want)
+	}
+	for _, handler := range recognizer.handlers {
+		handler(context.Background(), SpeechRecognitionCanceledEventArgs{
+			RecognitionEventArgs: RecognitionEventArgs{Type: ServerEventChunkSizeExceededError},
+		})
+	}
+	if got, want := writer.chunkSize.Load(), int64(2000); got != want {
+		t.Fatalf("chunkSize = %d, want %d", got, want)
+	}
+}
+
+func TestAudioWriterResendsRejectedChunks(t *testing.T) {
+	t.Parallel()
+
+	wsConn := &captureWebSocketConn{}
+	recognizer := NewRecognizer(context.Background(), &Conn{
+		conn:        wsConn,
+		logger:      NopLogger{},
+		sampleRate:  8000,
+		audioFormat: AudioFormatUlaw_8000,
+	})
+	writer := recognizer.AudioWriter(AudioWriterOptions{ChunkDuration: 100 * time.Millisecond})
+	rejected := bytes.Repeat([]byte{1}, 800)
+	if _, err := writer.Write(rejected); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, handler := range recognizer.handlers {
+		handler(context.Background(), SpeechRecognitionCanceledEventArgs{
+			RecognitionEventArgs: RecognitionEventArgs{Type: ServerEventChunkSizeExceededError},
+		})
+	}
+	if _, err := writer.Write(bytes.Repeat([]byte{2}, 400)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var chunks [][]byte
+	for _, data := range wsConn.writes {
+		var payload InputAudioChunkEvent
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if payload.Commit {
+			continue
+		}
+		audio, err := base64.StdEncoding.DecodeString(payload.Audio)
+		if err != nil {
+			t.Fatalf("DecodeString() error = %v", err)
+		}
+		chunks = append(chunks, audio)
+	}
+	want := [][]byte{rejected, rejected[:400], rejected[400:], bytes.Repeat([]byte{2}, 400)}
+	if got := len(chunks); got != len(want) {
+		t.Fatalf("len(chunks) = %d, want %d", got, len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(chunks[i], want[i]) {
+			t.Fatalf("chunk[%d] = %d bytes of %v, want %d bytes of %v", i, len(chunks[i]), chunks[i][0], len(want[i]), want[i][0])
+		}
+	}
+}
+
+func TestAudioWriterPacesToRealtime(t *testing.T) {
+	t.Parallel()
+
+	recognizer := NewRecognizer(context.Background(), &Conn{
+		conn:       &captureWebSocketConn{},
+		logger:     NopLogger{},
+		sampleRate: 16000,
+	})
+	writer := recognizer.AudioWriter(AudioWriterOptions{ChunkDuration: 20 * time.Millisecond, Realtime: true})
+
+	start := time.Now()
+	if _, err := writer.Write(make([]byte, 16000*2/10)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Fatalf("100ms of audio sent in %v, want paced delivery", elapsed)
+	}
+}
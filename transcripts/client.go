@@ -2,9 +2,12 @@ package transcripts
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"time"
 )
 
 const (
@@ -14,19 +17,21 @@ const (
 
 type Client struct {
 	config ClientConfig
+	// http is config.HTTPClient chained with config.HTTPMiddleware once, so that stateful
+	// middlewares are shared by all calls.
+	http *http.Client
 }
 
 // NewClient creates new OpenAI Realtime API client for specified auth token.
 func NewClient(authKey string) *Client {
-	return &Client{
-		config: DefaultConfig(authKey),
-	}
+	return NewClientWithConfig(DefaultConfig(authKey))
 }
 
 // NewClientWithConfig creates new OpenAI Realtime API client for specified config.
 func NewClientWithConfig(config ClientConfig) *Client {
 	return &Client{
 		config: config,
+		http:   ChainHTTPClient(config.HTTPClient, config.HTTPMiddleware...),
 	}
 }
 
@@ -37,6 +42,13 @@ func (c *Client) getURL(query url.Values) string {
 	return c.config.BaseURL
 }
 
+func (c *Client) logger() Logger {
+	if c.config.Logger != nil {
+		return c.config.Logger
+	}
+	return NopLogger{}
+}
+
 func (c *Client) getHeaders() http.Header {
 	headers := http.Header{}
 	if c.config.authKey != "" {
@@ -46,11 +58,15 @@ func (c *Client) getHeaders() http.Header {
 }
 
 type connectOption struct {
-	dialer     WebSocketDialer
-	logger     Logger
-	queries    map[string]string
-	keyterms   []string
-	sampleRate int64
+	dialer         WebSocketDialer
+	middleware     []WebSocketMiddleware
+	logger         Logger
+	queries        map[string]string
+	keyterms       []string
+	sampleRate     int64
+	audioFormat    AudioFormat
+	commitStrategy CommitStrategy
+	latency        LatencyObserver
 }
 type ConnectOption func(*connectOption)
 
@@ -138,6 +154,7 @@ func WithQuery(query map[string]string) ConnectOption {
 			case "audio_format":
 				if sampleRate, ok := sampleRateForAudioFormat(AudioFormat(v)); ok {
 					opts.sampleRate = sampleRate
+					opts.audioFormat = AudioFormat(v)
 				}
 			case "keyterms":
 				opts.keyterms = append(opts.keyterms, v)
@@ -165,6 +182,7 @@ func WithRealtimeConfig(cfg RealtimeConfig) ConnectOption {
 			opts.queries["audio_format"] = string(cfg.AudioFormat)
 			if sampleRate, ok := sampleRateForAudioFormat(cfg.AudioFormat); ok {
 				opts.sampleRate = sampleRate
+				opts.audioFormat = cfg.AudioFormat
 			}
 		}
 		if cfg.LanguageCode != "" {
@@ -172,6 +190,7 @@ func WithRealtimeConfig(cfg RealtimeConfig) ConnectOption {
 		}
 		if cfg.CommitStrategy != "" {
 			opts.queries["commit_strategy"] = string(cfg.CommitStrategy)
+			opts.commitStrategy = cfg.CommitStrategy
 		}
 		if cfg.NoVerbatim != nil {
 			opts.queries["no_verbatim"] = strconv.FormatBool(*cfg.NoVerbatim)
@@ -204,6 +223,14 @@ func WithDialer(dialer WebSocketDialer) ConnectOption {
 	}
 }
 
+// WithWebSocketMiddleware adds middlewares for the connection. They run inside the ones of
+// ClientConfig.WebSocketMiddleware.
+func WithWebSocketMiddleware(middlewares ...WebSocketMiddleware) ConnectOption {
+	return func(opts *connectOption) {
+		opts.middleware = append(opts.middleware, middlewares...)
+	}
+}
+
 // WithLogger sets the logger for the connection.
 func WithLogger(logger Logger) ConnectOption {
 	return func(opts *connectOption) {
@@ -214,13 +241,14 @@ func WithLogger(logger Logger) ConnectOption {
 // Connect connects to the Realtime API.
 func (c *Client) Connect(ctx context.Context, opts ...ConnectOption) (*Conn, error) {
 	connectOpts := connectOption{
-		logger: NopLogger{},
+		logger: c.logger(),
 		queries: map[string]string{
 			"model_id":           ModelScribeV2Realtime,
 			"audio_format":       string(AudioFormatPcm_16000),
 			"include_timestamps": "true",
 		},
-		sampleRate: 16000,
+		sampleRate:  16000,
+		audioFormat: AudioFormatPcm_16000,
 	}
 	for _, opt := range opts {
 		opt(&connectOpts)
@@ -228,6 +256,7 @@ func (c *Client) Connect(ctx context.Context, opts ...ConnectOption) (*Conn, err
 	if connectOpts.dialer == nil {
 		connectOpts.dialer = DefaultDialer()
 	}
+	connectOpts.dialer = ChainDialer(connectOpts.dialer, slices.Concat(c.config.WebSocketMiddleware, connectOpts.middleware)...)
 
 	// default headers
 	headers := c.getHeaders()
@@ -243,16 +272,29 @@ func (c *Client) Connect(ctx context.Context, opts ...ConnectOption) (*Conn, err
 	uri := c.getURL(query)
 
 	// dial
-	conn, err := connectOpts.dialer.Dial(ctx, uri, headers)
+	dialStart := time.Now()
+	wsConn, err := connectOpts.dialer.Dial(ctx, uri, headers)
+	dialAttrs := []slog.Attr{slog.String("url", redactRawURL(uri)), slog.Duration("duration", time.Since(dialStart))}
 	if err != nil {
+		LogAttrs(ctx, connectOpts.logger, slog.LevelDebug, "websocket dial failed", append(dialAttrs, slog.Any("error", err))...)
 		return nil, err
 	}
+	if resp := wsConn.Response(); resp != nil {
+		dialAttrs = append(dialAttrs, slog.String("request_id", resp.Header.Get("request-id")))
+	}
+	LogAttrs(ctx, connectOpts.logger, slog.LevelDebug, "websocket connected", dialAttrs...)
 
-	return &Conn{
-		conn:       conn,
-		logger:     connectOpts.logger,
-		sampleRate: connectOpts.sampleRate,
-	}, nil
+	conn := &Conn{
+		conn:           wsConn,
+		logger:         connectOpts.logger,
+		sampleRate:     connectOpts.sampleRate,
+		audioFormat:    connectOpts.audioFormat,
+		commitStrategy: connectOpts.commitStrategy,
+		dialDuration:   time.Since(dialStart),
+		latency:        connectOpts.latency,
+	}
+	conn.observeLatency(ctx, LatencyMeasurement{Metric: LatencyDial, Duration: conn.dialDuration})
+	return conn, nil
 }
 
 func sampleRateForAudioFormat(format AudioFormat) (int64, bool) {
@@ -273,3 +315,10 @@ func sampleRateForAudioFormat(format AudioFormat) (int64, bool) {
 		return 0, false
 	}
 }
+
+func bytesPerSampleForAudioFormat(format AudioFormat) int {
+	if format == AudioFormatUlaw_8000 {
+		return 1
+	}
+	return 2
+}
@@ -15,6 +15,15 @@ type ClientConfig struct {
 	BaseURL     string       // Base URL for the realtime API.
 	HTTPBaseURL string       // Base URL for the HTTP API.
 	HTTPClient  *http.Client // HTTP client for non-streaming transcription.
+	// Logger receives debug logs of HTTP requests and is the default logger of realtime connections.
+	// Use a StructuredLogger such as SlogLogger to get the fields as attributes.
+	Logger Logger
+	// HTTPMiddleware wraps the transport of every HTTP call, the first entry outermost. Use it to add
+	// headers, audit requests or rewrite URLs without replacing HTTPClient.
+	HTTPMiddleware []HTTPMiddleware
+	// WebSocketMiddleware intercepts the dial, reads and writes of every realtime connection,
+	// the first entry outermost.
+	WebSocketMiddleware []WebSocketMiddleware
 }
 
 func DefaultConfig(authKey string) ClientConfig {
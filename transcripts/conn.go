@@ -3,13 +3,20 @@ package transcripts
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // Conn is a connection to the OpenAI Realtime API.
 type Conn struct {
-	logger     Logger
-	conn       WebSocketConn
-	sampleRate int64
+	logger      Logger
+	conn        WebSocketConn
+	sampleRate  int64
+	audioFormat AudioFormat
+	// commitStrategy is the commit_strategy requested at connect, if any.
+	commitStrategy CommitStrategy
+
+	dialDuration time.Duration
+	latency      LatencyObserver
 }
 
 // Close closes the connection.
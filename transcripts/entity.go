@@ -0,0 +1,264 @@
+package transcripts
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// EntityCategory is a category of entities to detect or redact in a transcript, or a specific
+// entity type such as "email_address" or "credit_card_number".
+type EntityCategory string
+
+const (
+	EntityCategoryAll               EntityCategory = "all"
+	EntityCategoryPII               EntityCategory = "pii"
+	EntityCategoryPHI               EntityCategory = "phi"
+	EntityCategoryPCI               EntityCategory = "pci"
+	EntityCategoryOther             EntityCategory = "other"
+	EntityCategoryOffensiveLanguage EntityCategory = "offensive_language"
+)
+
+// entityTypes are the specific entity types the API documents, grouped by category.
+var entityTypes = map[EntityCategory]bool{
+	// pii
+	"name": true, "email_address": true, "phone_number": true, "address": true, "location": true,
+	"date_of_birth": true, "age": true, "gender": true, "nationality": true, "ssn": true,
+	"passport_number": true, "driver_license": true, "tax_id": true, "ip_address": true,
+	"username": true, "password": true, "vehicle_id": true, "account_number": true,
+	// phi
+	"medical_condition": true, "medication": true, "medical_process": true, "blood_type": true,
+	"injury": true, "health_plan_number": true, "medical_record_number": true,
+	// pci
+	"credit_card_number": true, "credit_card_expiration": true, "cvv": true,
+	"bank_account_number": true, "routing_number": true, "iban": true, "swift_code": true,
+	// other
+	"organization": true, "url": true, "date": true, "time": true, "money": true,
+	// offensive_language
+	"profanity": true,
+}
+
+// Valid reports whether c is a documented entity category or entity type.
+func (c EntityCategory) Valid() bool {
+	switch c {
+	case EntityCategoryAll,
+		EntityCategoryPII,
+		EntityCategoryPHI,
+		EntityCategoryPCI,
+		EntityCategoryOther,
+		EntityCategoryOffensiveLanguage:
+		return true
+	}
+	return entityTypes[c]
+}
+
+// RedactionMode controls what replaces a redacted entity.
+type RedactionMode string
+
+const (
+	// RedactionModeRedacted replaces entities with [REDACTED].
+	RedactionModeRedacted RedactionMode = "redacted"
+	// RedactionModeEntityType replaces entities with their type, e.g. [CREDIT_CARD_NUMBER].
+	RedactionModeEntityType RedactionMode = "entity_type"
+	// RedactionModeEnumeratedEntityType replaces entities with their numbered type, e.g. [NAME_1].
+	// Repeated occurrences of the same text share a number.
+	RedactionModeEnumeratedEntityType RedactionMode = "enumerated_entity_type"
+)
+
+// Valid reports whether m is a documented redaction mode.
+func (m RedactionMode) Valid() bool {
+	switch m {
+	case RedactionModeRedacted, RedactionModeEntityType, RedactionModeEnumeratedEntityType:
+		return true
+	default:
+		return false
+	}
+}
+
+func validateEntityOptions(req TranscriptionRequest) error {
+	for _, category := range req.EntityDetection {
+		if !category.Valid() {
+			return fmt.Errorf("unknown entity_detection category or entity type: %q", category)
+		}
+	}
+	if req.EntityRedaction != "" && !req.EntityRedaction.Valid() {
+		return fmt.Errorf("unknown entity_redaction category or entity type: %q", req.EntityRedaction)
+	}
+	if req.EntityRedactionMode != "" {
+		if !req.EntityRedactionMode.Valid() {
+			return fmt.Errorf("invalid entity_redaction_mode: %q", req.EntityRedactionMode)
+		}
+		if req.EntityRedaction == "" {
+			return fmt.Errorf("entity_redaction_mode requires entity_redaction")
+		}
+	}
+	return nil
+}
+
+// RedactTranscription returns a copy of resp with every entity in resp.Entities
+// removed from Text, Words and the entities themselves.
+// Entity spans are rune offsets into Text. Words covering a span are merged into a single
+// placeholder word that keeps the timing of the original words and the text around the span, such
+// as trailing punctuation. A word that cannot be found in Text is replaced with [REDACTED], since it
+// might be part of an entity. An empty mode means RedactionModeRedacted.
+func RedactTranscription(resp *TranscriptionResponse, mode RedactionMode) *TranscriptionResponse {
+	if resp == nil {
+		return nil
+	}
+	if mode == "" {
+		mode = RedactionModeRedacted
+	}
+	out := *resp
+	out.Headers = resp.Headers.Clone()
+	if len(resp.Entities) == 0 {
+		out.Words = append([]TranscriptionWord(nil), resp.Words...)
+		return &out
+	}
+
+	text := []rune(resp.Text)
+	entities := append([]TranscriptEntity(nil), resp.Entities...)
+	sort.SliceStable(entities, func(i, j int) bool {
+		return entities[i].StartChar < entities[j].StartChar
+	})
+	placeholders := entityPlaceholders(entities, mode)
+
+	// Rewrite the text and move each entity span onto its placeholder.
+	var (
+		builder strings.Builder
+		cursor  int
+		written int
+	)
+	spans := make([][2]int, len(entities))
+	for i, entity := range entities {
+		start := clamp(entity.StartChar, cursor, len(text))
+		end := clamp(entity.EndChar, start, len(text))
+		spans[i] = [2]int{start, end}
+		builder.WriteString(string(text[cursor:start]))
+		written += start - cursor
+		entities[i].Text = placeholders[i]
+		entities[i].StartChar = written
+		builder.WriteString(placeholders[i])
+		written += utf8.RuneCountInString(placeholders[i])
+		entities[i].EndChar = written
+		cursor = end
+	}
+	builder.WriteString(string(text[cursor:]))
+	out.Text = builder.String()
+	out.Entities = entities
+	out.Words = redactWords(resp.Words, resp.Text, spans, placeholders)
+	return &out
+}
+
+func redactWords(words []TranscriptionWord, text string, spans [][2]int, placeholders []string) []TranscriptionWord {
+	// runeIndex maps byte offsets in text to rune offsets, which entity spans use.
+	runeIndex := make([]int, len(text)+1)
+	n := 0
+	for i := range text {
+		runeIndex[i] = n
+		n++
+	}
+	runeIndex[len(text)] = n
+	runes := []rune(text)
+
+	out := make([]TranscriptionWord, 0, len(words))
+	merged := -1
+	cursor := 0
+	for _, word := range words {
+		index := -1
+		if word.Text != "" {
+			index = strings.Index(text[cursor:], word.Text)
+		}
+		if index < 0 {
+			if strings.TrimSpace(word.Text) != "" {
+				word.Text = "[REDACTED]"
+				word.Type = "word"
+			}
+			out = append(out, word)
+			merged = -1
+			continue
+		}
+		start := runeIndex[cursor+index]
+		cursor += index + len(word.Text)
+		end := runeIndex[cursor]
+
+		// Replace the parts of the word inside spans, writing each placeholder once.
+		var (
+			builder strings.Builder
+			first   = -1
+			prev    = merged
+			pos     = start
+		)
+		for i, s := range spans {
+			if start >= s[1] || end <= s[0] {
+				continue
+			}
+			if first < 0 {
+				first = i
+			}
+			builder.WriteString(string(runes[pos:max(pos, s[0])]))
+			if i != prev {
+				builder.WriteString(placeholders[i])
+			}
+			pos = max(pos, min(s[1], end))
+			merged = i
+		}
+		if first < 0 {
+			out = append(out, word)
+			merged = -1
+			continue
+		}
+		builder.WriteString(string(runes[pos:end]))
+		if first == prev && start >= spans[first][0] {
+			last := &out[len(out)-1]
+			last.Text += builder.String()
+			if word.End > last.End {
+				last.End = word.End
+			}
+			continue
+		}
+		word.Text = builder.String()
+		word.Type = "word"
+		out = append(out, word)
+	}
+	return out
+}
+
+func entityPlaceholders(entities []TranscriptEntity, mode RedactionMode) []string {
+	placeholders := make([]string, len(entities))
+	counters := make(map[string]int)
+	seen := make(map[string]int)
+	for i, entity := range entities {
+		label := strings.ToUpper(strings.ReplaceAll(entity.EntityType, " ", "_"))
+		if label == "" {
+			label = "REDACTED"
+		}
+		switch mode {
+		case RedactionModeEntityType:
+			placeholders[i] = "[" + label + "]"
+		case RedactionModeEnumeratedEntityType:
+			key := label + "\x00" + entity.Text
+			n, ok := seen[key]
+			if !ok {
+				counters[label]++
+				n = counters[label]
+				seen[key] = n
+			}
+			placeholders[i] = "[" + label + "_" + strconv.Itoa(n) + "]"
+		default:
+			placeholders[i] = "[REDACTED]"
+		}
+	}
+	return placeholders
+}
+
+func clamp(value, lo, hi int) int {
+	if value < lo {
+		return lo
+	}
+	if value > hi {
+		return hi
+	}
+	return value
+}
@@ -0,0 +1,118 @@
+package transcripts
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranscribeValidatesEntityOptions(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient("test-key")
+	tests := []TranscriptionRequest{
+		{EntityDetection: []EntityCategory{"email address"}},
+		{EntityDetection: []EntityCategory{"pil"}},
+		{EntityRedaction: "pci_"},
+		{EntityRedaction: "PII"},
+		{EntityRedaction: EntityCategoryPII, EntityRedactionMode: "masked"},
+		{EntityRedactionMode: RedactionModeRedacted},
+	}
+	for _, req := range tests {
+		req.ModelID = "scribe_v1"
+		req.SourceURL = "https://example.com/audio.mp3"
+		if _, err := client.Transcribe(context.Background(), req); err == nil {
+			t.Fatalf("Transcribe(%+v) error = nil, want non-nil", req)
+		}
+	}
+
+	req := TranscriptionRequest{EntityDetection: []EntityCategory{EntityCategoryPCI, "email_address"}, EntityRedaction: "credit_card_number"}
+	if err := validateEntityOptions(req); err != nil {
+		t.Fatalf("validateEntityOptions(%+v) error = %v, want nil", req, err)
+	}
+	err := validateEntityOptions(TranscriptionRequest{EntityDetection: []EntityCategory{EntityCategoryPII, "pil"}})
+	if err == nil || !strings.Contains(err.Error(), `"pil"`) {
+		t.Fatalf("validateEntityOptions() error = %v, want it to name \"pil\"", err)
+	}
+}
+
+func TestRedactTranscriptionRewritesTextWordsAndEntities(t *testing.T) {
+	t.Parallel()
+
+	resp := &TranscriptionResponse{
+		Text: "Call John Smith at 555, John Smith.",
+		Words: []TranscriptionWord{
+			{Text: "Call", Start: 0.0, End: 0.2, Type: "word"},
+			{Text: " ", Start: 0.2, End: 0.3, Type: "spacing"},
+			{Text: "John", Start: 0.3, End: 0.5, Type: "word"},
+			{Text: " ", Start: 0.5, End: 0.55, Type: "spacing"},
+			{Text: "Smith", Start: 0.55, End: 0.9, Type: "word"},
+			{Text: " ", Start: 0.9, End: 1.0, Type: "spacing"},
+			{Text: "at", Start: 1.0, End: 1.1, Type: "word"},
+			{Text: " ", Start: 1.1, End: 1.2, Type: "spacing"},
+			{Text: "555,", Start: 1.2, End: 1.6, Type: "word"},
+			{Text: " ", Start: 1.6, End: 1.7, Type: "spacing"},
+			{Text: "John", Start: 1.7, End: 1.9, Type: "word"},
+			{Text: " ", Start: 1.9, End: 2.0, Type: "spacing"},
+			{Text: "Smith.", Start: 2.0, End: 2.4, Type: "word"},
+		},
+		Entities: []TranscriptEntity{
+			{Text: "555", EntityType: "phone_number", StartChar: 19, EndChar: 22},
+			{Text: "John Smith", EntityType: "name", StartChar: 5, EndChar: 15},
+			{Text: "John Smith", EntityType: "name", StartChar: 24, EndChar: 34},
+		},
+	}
+
+	out := RedactTranscription(resp, RedactionModeEnumeratedEntityType)
+	if got, want := out.Text, "Call [NAME_1] at [PHONE_NUMBER_1], [NAME_1]."; got != want {
+		t.Fatalf("Text = %q, want %q", got, want)
+	}
+	var words []string
+	for _, word := range out.Words {
+		words = append(words, word.Text)
+	}
+	if got, want := strings.Join(words, ""), "Call [NAME_1] at [PHONE_NUMBER_1], [NAME_1]."; got != want {
+		t.Fatalf("words = %q, want %q", got, want)
+	}
+	if got, want := out.Words[2].End, 0.9; got != want {
+		t.Fatalf("Words[2].End = %v, want %v", got, want)
+	}
+	for _, entity := range out.Entities {
+		if strings.Contains(entity.Text, "John") || strings.Contains(entity.Text, "555") {
+			t.Fatalf("entity text leaked: %q", entity.Text)
+		}
+		if got, want := out.Text[entity.StartChar:entity.EndChar], entity.Text; got != want {
+			t.Fatalf("entity span = %q, want %q", got, want)
+		}
+	}
+	if got, want := resp.Text, "Call John Smith at 555, John Smith."; got != want {
+		t.Fatalf("original Text mutated to %q", got)
+	}
+}
+
+func TestRedactTranscriptionRedactsWordsMissingFromText(t *testing.T) {
+	t.Parallel()
+
+	resp := &TranscriptionResponse{
+		Text: "My card is 4111.",
+		Words: []TranscriptionWord{
+			{Text: "My", Type: "word"},
+			{Text: " ", Type: "spacing"},
+			{Text: "card", Type: "word"},
+			{Text: " ", Type: "spacing"},
+			{Text: "is", Type: "word"},
+			{Text: " ", Type: "spacing"},
+			{Text: "4 1 1 1.", Type: "word"},
+		},
+		Entities: []TranscriptEntity{{Text: "4111", EntityType: "credit_card_number", StartChar: 11, EndChar: 15}},
+	}
+
+	out := RedactTranscription(resp, RedactionModeEntityType)
+	var words []string
+	for _, word := range out.Words {
+		words = append(words, word.Text)
+	}
+	if got, want := strings.Join(words, ""), "My card is [REDACTED]"; got != want {
+		t.Fatalf("words = %q, want %q", got, want)
+	}
+}
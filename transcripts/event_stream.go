@@ -0,0 +1,159 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// OverflowPolicy decides what an EventStream does when a consumer falls behind.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the read loop until the consumer catches up.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropPartials drops the oldest buffered partial transcript to make room for a newer one.
+	// Committed transcripts and errors still block.
+	OverflowDropPartials
+	// OverflowError stops the recognizer with ErrEventStreamOverflow.
+	OverflowError
+)
+
+// ErrEventStreamOverflow is returned by Recognizer.Err when an EventStream with OverflowError is full.
+var ErrEventStreamOverflow = errors.New("event stream overflow")
+
+// EventStreamOptions configures an EventStream.
+type EventStreamOptions struct {
+	// BufferSize is the capacity of each channel. Defaults to 16.
+	BufferSize int
+	// Overflow is the policy applied when a channel is full.
+	Overflow OverflowPolicy
+	// IncludeTimestamps delivers committed_transcript_with_timestamps events on Committed
+	// instead of plain committed_transcript events. Enable it when the connection requests timestamps.
+	IncludeTimestamps bool
+}
+
+// EventStream delivers recognizer events on typed channels instead of callbacks,
+// so slow consumers don't run on the websocket read goroutine.
+// All channels are closed when the recognizer exits.
+type EventStream struct {
+	opts      EventStreamOptions
+	session   chan SessionStartEventArgs
+	partials  chan SpeechRecognizingEventArgs
+	committed chan SpeechRecognizedWithTimestampEventArgs
+	errors    chan SpeechRecognitionCanceledEventArgs
+
+	mu  sync.Mutex
+	err error
+}
+
+// Events returns an EventStream fed by this recognizer.
+// It must be called before Start.
+func (r *Recognizer) Events(opts EventStreamOptions) *EventStream {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 16
+	}
+	s := &EventStream{
+		opts:      opts,
+		session:   make(chan SessionStartEventArgs, 1),
+		partials:  make(chan SpeechRecognizingEventArgs, opts.BufferSize),
+		committed: make(chan SpeechRecognizedWithTimestampEventArgs, opts.BufferSize),
+		errors:    make(chan SpeechRecognitionCanceledEventArgs, opts.BufferSize),
+	}
+	r.handlers = append(r.handlers, s.handle)
+	r.streams = append(r.streams, s)
+	return s
+}
+
+// Session receives the session_started event.
+func (s *EventStream) Session() <-chan SessionStartEventArgs {
+	return s.session
+}
+
+// Partials receives partial transcripts.
+func (s *EventStream) Partials() <-chan SpeechRecognizingEventArgs {
+	return s.partials
+}
+
+// Committed receives committed transcripts.
+// Words are only populated when IncludeTimestamps is set.
+func (s *EventStream) Committed() <-chan SpeechRecognizedWithTimestampEventArgs {
+	return s.committed
+}
+
+// Errors receives error events sent by the server.
+func (s *EventStream) Errors() <-chan SpeechRecognitionCanceledEventArgs {
+	return s.errors
+}
+
+// Err returns ErrEventStreamOverflow once the stream overflowed under OverflowError.
+func (s *EventStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *EventStream) handle(ctx context.Context, event ServerEvent) {
+	if s.Err() != nil {
+		return
+	}
+	switch e := event.(type) {
+	case SessionStartEventArgs:
+		select {
+		case s.session <- e:
+		default:
+		}
+	case SpeechRecognizingEventArgs:
+		if s.opts.Overflow == OverflowDropPartials {
+			for {
+				select {
+				case s.partials <- e:
+					return
+				default:
+				}
+				select {
+				case <-s.partials:
+				default:
+				}
+			}
+		}
+		deliver(ctx, s, s.partials, e)
+	case SpeechRecognizedEventArgs:
+		if !s.opts.IncludeTimestamps {
+			deliver(ctx, s, s.committed, SpeechRecognizedWithTimestampEventArgs{
+				RecognitionEventArgs: e.RecognitionEventArgs,
+				Text:                 e.Text,
+			})
+		}
+	case SpeechRecognizedWithTimestampEventArgs:
+		if s.opts.IncludeTimestamps {
+			deliver(ctx, s, s.committed, e)
+		}
+	case SpeechRecognitionCanceledEventArgs:
+		deliver(ctx, s, s.errors, e)
+	}
+}
+
+func deliver[T any](ctx context.Context, s *EventStream, ch chan T, value T) {
+	if s.opts.Overflow == OverflowError {
+		select {
+		case ch <- value:
+		default:
+			s.mu.Lock()
+			s.err = ErrEventStreamOverflow
+			s.mu.Unlock()
+		}
+		return
+	}
+	select {
+	case ch <- value:
+	case <-ctx.Done():
+	}
+}
+
+func (s *EventStream) close() {
+	close(s.session)
+	close(s.partials)
+	close(s.committed)
+	close(s.errors)
+}
@@ -0,0 +1,149 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedWebSocketConn replays server messages and records client writes.
+type scriptedWebSocketConn struct {
+	reads chan []byte
+	// onWrite, when set, is called with every client message and returns server replies.
+	onWrite func(data []byte) []string
+
+	mu     sync.Mutex
+	writes [][]byte
+	closed bool
+}
+
+func newScriptedWebSocketConn(messages ...string) *scriptedWebSocketConn {
+	c := &scriptedWebSocketConn{reads: make(chan []byte, len(messages)+16)}
+	for _, msg := range messages {
+		c.reads <- []byte(msg)
+	}
+	return c
+}
+
+func (c *scriptedWebSocketConn) ReadMessage(ctx context.Context) (MessageType, []byte, error) {
+	select {
+	case data, ok := <-c.reads:
+		if !ok {
+			return 0, nil, Permanent(io.EOF)
+		}
+		return MessageText, data, nil
+	case <-ctx.Done():
+		return 0, nil, Permanent(ctx.Err())
+	}
+}
+
+func (c *scriptedWebSocketConn) WriteMessage(_ context.Context, _ MessageType, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writes = append(c.writes, append([]byte(nil), data...))
+	if c.onWrite != nil && !c.closed {
+		for _, reply := range c.onWrite(data) {
+			c.reads <- []byte(reply)
+		}
+	}
+	return nil
+}
+
+func (c *scriptedWebSocketConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.reads)
+	}
+	return nil
+}
+
+func (c *scriptedWebSocketConn) Response() *http.Response {
+	return &http.Response{Header: make(http.Header)}
+}
+
+func (c *scriptedWebSocketConn) Ping(context.Context) error {
+	return nil
+}
+
+func (c *scriptedWebSocketConn) written() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.writes...)
+}
+
+func TestRecognizerEventsDeliversTypedChannels(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn(
+		`{"message_type":"session_started","session_id":"sess_1"}`,
+		`{"message_type":"partial_transcript","text":"hel"}`,
+		`{"message_type":"committed_transcript","text":"hello"}`,
+		`{"message_type":"committed_transcript_with_timestamps","text":"hello","words":[{"text":"hello","start":0,"end":0.5}]}`,
+		`{"message_type":"rate_limited","error":"slow down"}`,
+	)
+	_ = wsConn.Close()
+
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}, sampleRate: 16000})
+	stream := recognizer.Events(EventStreamOptions{IncludeTimestamps: true})
+	recognizer.Start()
+
+	if got, want := (<-stream.Session()).SessionID, "sess_1"; got != want {
+		t.Fatalf("SessionID = %s, want %s", got, want)
+	}
+	if got, want := (<-stream.Partials()).Text, "hel"; got != want {
+		t.Fatalf("partial = %s, want %s", got, want)
+	}
+	committed := <-stream.Committed()
+	if got, want := len(committed.Words), 1; got != want {
+		t.Fatalf("len(Words) = %d, want %d", got, want)
+	}
+	if got, want := (<-stream.Errors()).Error, "slow down"; got != want {
+		t.Fatalf("error = %s, want %s", got, want)
+	}
+	if _, ok := <-stream.Committed(); ok {
+		t.Fatal("Committed() should be closed after the recognizer exits")
+	}
+}
+
+func TestRecognizerEventsOverflowPolicies(t *testing.T) {
+	t.Parallel()
+
+	messages := []string{
+		`{"message_type":"partial_transcript","text":"a"}`,
+		`{"message_type":"partial_transcript","text":"ab"}`,
+		`{"message_type":"partial_transcript","text":"abc"}`,
+	}
+
+	wsConn := newScriptedWebSocketConn(messages...)
+	_ = wsConn.Close()
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}})
+	stream := recognizer.Events(EventStreamOptions{BufferSize: 1, Overflow: OverflowDropPartials})
+	recognizer.Start()
+	select {
+	case <-recognizer.Err():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for recognizer to exit")
+	}
+	if got, want := (<-stream.Partials()).Text, "abc"; got != want {
+		t.Fatalf("partial = %s, want %s", got, want)
+	}
+
+	wsConn = newScriptedWebSocketConn(messages...)
+	recognizer = NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}})
+	_ = recognizer.Events(EventStreamOptions{BufferSize: 1, Overflow: OverflowError})
+	recognizer.Start()
+	select {
+	case err := <-recognizer.Err():
+		if !errors.Is(err, ErrEventStreamOverflow) {
+			t.Fatalf("Err() = %v, want %v", err, ErrEventStreamOverflow)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for overflow error")
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func (c *Client) Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error) {
@@ -74,10 +76,10 @@ func (c *Client) Transcribe(ctx context.Context, req TranscriptionRequest) (*Tra
 			return nil, err
 		}
 	}
-	if err := writeMultipartField(writer, "entity_redaction", req.EntityRedaction); err != nil {
+	if err := writeMultipartField(writer, "entity_redaction", string(req.EntityRedaction)); err != nil {
 		return nil, err
 	}
-	if err := writeMultipartField(writer, "entity_redaction_mode", req.EntityRedactionMode); err != nil {
+	if err := writeMultipartField(writer, "entity_redaction_mode", string(req.EntityRedactionMode)); err != nil {
 		return nil, err
 	}
 	if len(req.Keyterms) > 0 {
@@ -137,7 +139,7 @@ func (c *Client) Transcribe(ctx context.Context, req TranscriptionRequest) (*Tra
 		httpReq.URL.RawQuery = query.Encode()
 	}
 
-	resp, err := c.getHTTPClient().Do(httpReq)
+	resp, err := c.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -163,11 +165,26 @@ func writeMultipartField(writer *multipart.Writer, name, value string) error {
 	return writer.WriteField(name, value)
 }
 
-func (c *Client) getHTTPClient() *http.Client {
-	if c.config.HTTPClient != nil {
-		return c.config.HTTPClient
+// do sends req and logs it at debug level.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.getHTTPClient().Do(req)
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", RedactURL(req.URL)),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		LogAttrs(req.Context(), c.config.Logger, slog.LevelDebug, "http request failed", append(attrs, slog.Any("error", err))...)
+		return nil, err
 	}
-	return http.DefaultClient
+	attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.String("request_id", resp.Header.Get("request-id")))
+	LogAttrs(req.Context(), c.config.Logger, slog.LevelDebug, "http request", attrs...)
+	return resp, nil
+}
+
+func (c *Client) getHTTPClient() *http.Client {
+	return c.http
 }
 
 func (c *Client) getTranscribeURL() string {
@@ -213,5 +230,5 @@ func (c *Client) validateTranscriptionRequest(req TranscriptionRequest) error {
 	if req.File != nil && req.FileName == "" {
 		return fmt.Errorf("file name is required")
 	}
-	return nil
+	return validateEntityOptions(req)
 }
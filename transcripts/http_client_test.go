@@ -148,9 +148,9 @@ func TestClientTranscribeSendsMultipartRequest(t *testing.T) {
 		Temperature:           &temperature,
 		Seed:                  &seed,
 		EnableLogging:         &enableLogging,
-		EntityDetection:       []string{"pii", "pci"},
-		EntityRedaction:       "pii",
-		EntityRedactionMode:   "redacted",
+		EntityDetection:       []EntityCategory{EntityCategoryPII, EntityCategoryPCI},
+		EntityRedaction:       EntityCategoryPII,
+		EntityRedactionMode:   RedactionModeRedacted,
 		Keyterms:              []string{"ElevenLabs", "Golang"},
 		AdditionalFormats: []TranscriptOutputFormatRequest{
 			{Format: "srt"},
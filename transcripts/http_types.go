@@ -23,17 +23,20 @@ type TranscriptionRequest struct {
 	EnableLogging         *bool
 	Webhook               *bool
 	WebhookMetadata       map[string]any
-	EntityDetection       []string
-	EntityRedaction       string
-	EntityRedactionMode   string
+	EntityDetection       []EntityCategory
+	EntityRedaction       EntityCategory
+	EntityRedactionMode   RedactionMode
 	Keyterms              []string
 	AdditionalFormats     []TranscriptOutputFormatRequest
 }
 
 type TranscriptionWord struct {
-	Text  string  `json:"text"`
-	Start float64 `json:"start,omitempty"`
-	End   float64 `json:"end,omitempty"`
+	Text      string  `json:"text"`
+	Start     float64 `json:"start,omitempty"`
+	End       float64 `json:"end,omitempty"`
+	Type      string  `json:"type,omitempty"`
+	SpeakerID string  `json:"speaker_id,omitempty"`
+	LogProb   float64 `json:"logprob,omitempty"`
 }
 
 type TranscriptAdditionalFormat struct {
@@ -43,10 +46,11 @@ type TranscriptAdditionalFormat struct {
 }
 
 type TranscriptEntity struct {
-	Text       string `json:"text,omitempty"`
-	EntityType string `json:"entity_type,omitempty"`
-	StartChar  int    `json:"start_char,omitempty"`
-	EndChar    int    `json:"end_char,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	EntityType string   `json:"entity_type,omitempty"`
+	StartChar  int      `json:"start_char,omitempty"`
+	EndChar    int      `json:"end_char,omitempty"`
+	Confidence *float64 `json:"confidence,omitempty"`
 }
 
 type TranscriptOutputFormatRequest struct {
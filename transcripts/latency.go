@@ -0,0 +1,141 @@
+package transcripts
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LatencyMetric names a latency measurement. The tts package reports through the same types.
+type LatencyMetric string
+
+const (
+	// LatencyDial is the time WebSocketDialer.Dial took to open a websocket.
+	LatencyDial LatencyMetric = "dial"
+	// LatencyTimeToFirstPartial is the time from the first audio sent to the first partial transcript.
+	LatencyTimeToFirstPartial LatencyMetric = "time_to_first_partial"
+	// LatencyCommitToFinal is the time from a commit to its committed transcript.
+	LatencyCommitToFinal LatencyMetric = "commit_to_final"
+	// LatencyTimeToFirstByte is the time from sending a TTS HTTP request to reading the first audio byte.
+	LatencyTimeToFirstByte LatencyMetric = "time_to_first_byte"
+	// LatencyTimeToFirstAudio is the time from the first text sent on a realtime TTS session
+	// to the first audio received.
+	LatencyTimeToFirstAudio LatencyMetric = "time_to_first_audio"
+)
+
+// LatencyMeasurement is one latency sample.
+type LatencyMeasurement struct {
+	Metric   LatencyMetric
+	Duration time.Duration
+	// RequestID is the request-id response header of the HTTP request or websocket handshake, if any.
+	RequestID string
+}
+
+// LatencyObserver receives latency measurements, for example to feed a histogram.
+// It is called synchronously and must not block.
+type LatencyObserver interface {
+	ObserveLatency(ctx context.Context, m LatencyMeasurement)
+}
+
+// LatencyObserverFunc adapts a function to a LatencyObserver.
+type LatencyObserverFunc func(ctx context.Context, m LatencyMeasurement)
+
+// ObserveLatency calls f.
+func (f LatencyObserverFunc) ObserveLatency(ctx context.Context, m LatencyMeasurement) {
+	f(ctx, m)
+}
+
+// WithLatencyObserver reports the dial time of the connection, and the latencies of a Recognizer
+// running on it, to observer.
+func WithLatencyObserver(observer LatencyObserver) ConnectOption {
+	return func(opts *connectOption) {
+		opts.latency = observer
+	}
+}
+
+// DialDuration returns the time it took to open the websocket.
+func (c *Conn) DialDuration() time.Duration {
+	return c.dialDuration
+}
+
+func (c *Conn) observeLatency(ctx context.Context, m LatencyMeasurement) {
+	if c.latency == nil {
+		return
+	}
+	if resp := c.conn.Response(); resp != nil {
+		m.RequestID = resp.Header.Get("request-id")
+	}
+	c.latency.ObserveLatency(ctx, m)
+}
+
+// RecognizerLatency holds the latencies measured on a Recognizer session. Zero means not measured yet.
+type RecognizerLatency struct {
+	Dial               time.Duration
+	TimeToFirstPartial time.Duration
+	// LastCommitToFinal is the latency of the most recent commit sent with Commit.
+	// It is not measured on CommitStrategyVAD sessions, where committed transcripts cannot be
+	// matched to the commits this client sent.
+	LastCommitToFinal time.Duration
+}
+
+// Latency returns the latencies measured so far.
+func (r *Recognizer) Latency() RecognizerLatency {
+	r.latency.mu.Lock()
+	defer r.latency.mu.Unlock()
+	return RecognizerLatency{
+		Dial:               r.conn.dialDuration,
+		TimeToFirstPartial: r.latency.firstPartial,
+		LastCommitToFinal:  r.latency.lastCommitToFinal,
+	}
+}
+
+type recognizerLatency struct {
+	mu                sync.Mutex
+	firstSend         time.Time
+	firstPartial      time.Duration
+	commits           []time.Time
+	vadCommits        bool
+	lastCommitToFinal time.Duration
+}
+
+func (r *Recognizer) markSent() {
+	r.latency.mu.Lock()
+	defer r.latency.mu.Unlock()
+	if r.latency.firstSend.IsZero() {
+		r.latency.firstSend = time.Now()
+	}
+}
+
+func (r *Recognizer) markCommit() {
+	r.latency.mu.Lock()
+	defer r.latency.mu.Unlock()
+	if r.latency.vadCommits || r.conn.commitStrategy == CommitStrategyVAD {
+		return
+	}
+	r.latency.commits = append(r.latency.commits, time.Now())
+}
+
+// observeEvent runs on the read goroutine. final reports whether msg completed a commit.
+func (r *Recognizer) observeEvent(msg ServerEvent, final bool) {
+	r.latency.mu.Lock()
+	var measurement *LatencyMeasurement
+	if started, ok := msg.(SessionStartEventArgs); ok && started.Config.CommitStrategy == CommitStrategyVAD {
+		r.latency.vadCommits = true
+		r.latency.commits = nil
+	}
+	switch {
+	case msg.ServerEventType() == ServerEventPartialTranscript:
+		if r.latency.firstPartial == 0 && !r.latency.firstSend.IsZero() {
+			r.latency.firstPartial = time.Since(r.latency.firstSend)
+			measurement = &LatencyMeasurement{Metric: LatencyTimeToFirstPartial, Duration: r.latency.firstPartial}
+		}
+	case final && len(r.latency.commits) > 0:
+		r.latency.lastCommitToFinal = time.Since(r.latency.commits[0])
+		r.latency.commits = r.latency.commits[1:]
+		measurement = &LatencyMeasurement{Metric: LatencyCommitToFinal, Duration: r.latency.lastCommitToFinal}
+	}
+	r.latency.mu.Unlock()
+	if measurement != nil {
+		r.conn.observeLatency(r.ctx, *measurement)
+	}
+}
@@ -0,0 +1,107 @@
+package transcripts
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecognizerMeasuresPartialAndCommitLatency(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn()
+	wsConn.onWrite = func(data []byte) []string {
+		if bytes.Contains(data, []byte(`"commit":true`)) {
+			return []string{`{"message_type":"committed_transcript","text":"hello"}`}
+		}
+		return []string{`{"message_type":"partial_transcript","text":"hel"}`}
+	}
+	var (
+		mu       sync.Mutex
+		observed []LatencyMetric
+	)
+	observer := LatencyObserverFunc(func(_ context.Context, m LatencyMeasurement) {
+		mu.Lock()
+		defer mu.Unlock()
+		if m.Duration <= 0 {
+			t.Errorf("%s duration = %v, want > 0", m.Metric, m.Duration)
+		}
+		observed = append(observed, m.Metric)
+	})
+	conn := &Conn{conn: wsConn, logger: NopLogger{}, sampleRate: 16000, dialDuration: time.Millisecond, latency: observer}
+	recognizer := NewRecognizer(context.Background(), conn)
+	recognizer.Start()
+
+	if err := recognizer.Send(make([]byte, 320)); err != nil {
+		t.Fatal(err)
+	}
+	if err := recognizer.Send(make([]byte, 320)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := recognizer.Finish(ctx); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	latency := recognizer.Latency()
+	if latency.Dial != time.Millisecond || latency.TimeToFirstPartial <= 0 || latency.LastCommitToFinal <= 0 {
+		t.Errorf("Latency() = %+v, want all measured", latency)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []LatencyMetric{LatencyTimeToFirstPartial, LatencyCommitToFinal}
+	if len(observed) != len(want) || observed[0] != want[0] || observed[1] != want[1] {
+		t.Errorf("observed = %v, want %v", observed, want)
+	}
+}
+
+func TestRecognizerSkipsCommitLatencyWithVADCommits(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn()
+	wsConn.onWrite = func(data []byte) []string {
+		if bytes.Contains(data, []byte(`"commit":true`)) {
+			// A server-side VAD commit arrives before the transcript of the client's commit.
+			return []string{
+				`{"message_type":"committed_transcript","text":"vad"}`,
+				`{"message_type":"committed_transcript","text":"hello"}`,
+			}
+		}
+		return nil
+	}
+	var (
+		mu       sync.Mutex
+		observed []LatencyMetric
+	)
+	observer := LatencyObserverFunc(func(_ context.Context, m LatencyMeasurement) {
+		mu.Lock()
+		defer mu.Unlock()
+		observed = append(observed, m.Metric)
+	})
+	conn := &Conn{conn: wsConn, logger: NopLogger{}, sampleRate: 16000, commitStrategy: CommitStrategyVAD, latency: observer}
+	recognizer := NewRecognizer(context.Background(), conn)
+	recognizer.Start()
+
+	if err := recognizer.Send(make([]byte, 320)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := recognizer.Finish(ctx); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	if got := recognizer.Latency().LastCommitToFinal; got != 0 {
+		t.Errorf("LastCommitToFinal = %v, want 0", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, metric := range observed {
+		if metric == LatencyCommitToFinal {
+			t.Errorf("observed %s on a VAD commit session", metric)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// StructuredLogger is a Logger that also accepts attributes. The SDK logs through LogAttrs when the
+// configured logger implements it, so request IDs, URLs, status codes, durations and event types
+// arrive as separate fields.
+type StructuredLogger interface {
+	Logger
+	LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+// SlogLogger is a StructuredLogger backed by a slog.Handler.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a SlogLogger that writes to handler.
+func NewSlogLogger(handler slog.Handler) *SlogLogger {
+	return &SlogLogger{logger: slog.New(handler)}
+}
+
+// With returns a SlogLogger that adds attrs to every record, for example a session or tenant ID.
+// The arguments are the same as for slog.Logger.With.
+func (l *SlogLogger) With(args ...any) *SlogLogger {
+	return &SlogLogger{logger: l.logger.With(args...)}
+}
+
+func (l *SlogLogger) Errorf(format string, v ...any) {
+	l.logger.Error(fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) Warnf(format string, v ...any) {
+	l.logger.Warn(fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) Infof(format string, v ...any) {
+	l.logger.Info(fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) Debugf(format string, v ...any) {
+	l.logger.Debug(fmt.Sprintf(format, v...))
+}
+
+// LogAttrs logs a structured record.
+func (l *SlogLogger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// LogAttrs logs msg with attrs to logger. A StructuredLogger receives the attributes as they are;
+// any other Logger receives "msg key=value ..." through the method for level. A nil logger logs nothing.
+func LogAttrs(ctx context.Context, logger Logger, level slog.Level, msg string, attrs ...slog.Attr) {
+	switch l := logger.(type) {
+	case nil, NopLogger:
+		return
+	case StructuredLogger:
+		l.LogAttrs(ctx, level, msg, attrs...)
+		return
+	}
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, attr := range attrs {
+		b.WriteByte(' ')
+		b.WriteString(attr.String())
+	}
+	line := b.String()
+	switch {
+	case level >= slog.LevelError:
+		logger.Errorf("%s", line)
+	case level >= slog.LevelWarn:
+		logger.Warnf("%s", line)
+	case level >= slog.LevelInfo:
+		logger.Infof("%s", line)
+	default:
+		logger.Debugf("%s", line)
+	}
+}
+
+func redactRawURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid url>"
+	}
+	return RedactURL(u)
+}
+
+// RedactURL returns u as a string with credentials in the user info or query string replaced.
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User("REDACTED")
+	}
+	query := redacted.Query()
+	changed := false
+	for key := range query {
+		switch strings.ToLower(key) {
+		case "token", "xi_api_key", "xi-api-key", "api_key":
+			query.Set(key, "REDACTED")
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
@@ -0,0 +1,61 @@
+package transcripts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"testing"
+)
+
+type captureLogger struct {
+	NopLogger
+	lines []string
+}
+
+func (l *captureLogger) Warnf(format string, v ...any) {
+	l.lines = append(l.lines, "WARN "+fmt.Sprintf(format, v...))
+}
+
+func TestLogAttrsWritesStructuredRecordsToSlog(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})).With("tenant", "acme")
+	LogAttrs(context.Background(), logger, slog.LevelDebug, "http request", slog.Int("status", 200), slog.String("request_id", "req_1"))
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("unmarshal %q: %v", buf.String(), err)
+	}
+	for key, want := range map[string]any{"msg": "http request", "status": float64(200), "request_id": "req_1", "tenant": "acme", "level": "DEBUG"} {
+		if got := record[key]; got != want {
+			t.Errorf("record[%s] = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestLogAttrsFallsBackToPrintfLogger(t *testing.T) {
+	t.Parallel()
+
+	logger := &captureLogger{}
+	LogAttrs(context.Background(), logger, slog.LevelWarn, "backing off", slog.String("event_type", "rate_limited"))
+	LogAttrs(context.Background(), nil, slog.LevelWarn, "ignored")
+	if got, want := len(logger.lines), 1; got != want {
+		t.Fatalf("lines = %q, want %d", logger.lines, want)
+	}
+	if got, want := logger.lines[0], "WARN backing off event_type=rate_limited"; got != want {
+		t.Errorf("line = %q, want %q", got, want)
+	}
+}
+
+func TestRedactURLHidesCredentials(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("wss://api.elevenlabs.io/v1/speech-to-text/realtime?model_id=scribe&token=secret")
+	if got, want := RedactURL(u), "wss://api.elevenlabs.io/v1/speech-to-text/realtime?model_id=scribe&token=REDACTED"; got != want {
+		t.Errorf("RedactURL() = %q, want %q", got, want)
+	}
+}
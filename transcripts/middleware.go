@@ -0,0 +1,129 @@
+package transcripts
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoundTripperFunc adapts a function to an http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// HTTPMiddleware wraps the transport of HTTP calls, for example to add headers, audit requests
+// or rewrite URLs. A middleware that changes the request must clone it first.
+type HTTPMiddleware func(next http.RoundTripper) http.RoundTripper
+
+// ChainHTTPClient returns a copy of base whose transport runs through middlewares. The first middleware
+// is the outermost. A nil base means http.DefaultClient.
+func ChainHTTPClient(base *http.Client, middlewares ...HTTPMiddleware) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	if len(middlewares) == 0 {
+		return base
+	}
+	transport := base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+	client := *base
+	client.Transport = transport
+	return &client
+}
+
+// DialFunc opens a websocket.
+type DialFunc func(ctx context.Context, url string, header http.Header) (WebSocketConn, error)
+
+// ReadFunc reads one websocket message.
+type ReadFunc func(ctx context.Context) (MessageType, []byte, error)
+
+// WriteFunc writes one websocket message.
+type WriteFunc func(ctx context.Context, messageType MessageType, data []byte) error
+
+// WebSocketMiddleware intercepts websocket traffic. Each hook receives the next step of the chain
+// and may change its arguments or results, or not call it at all. Nil hooks pass through.
+type WebSocketMiddleware struct {
+	// Dial intercepts opening the connection, for example to rewrite the URL or add headers.
+	Dial func(ctx context.Context, url string, header http.Header, next DialFunc) (WebSocketConn, error)
+	// Read intercepts every message read from the server.
+	Read func(ctx context.Context, next ReadFunc) (MessageType, []byte, error)
+	// Write intercepts every message written to the server.
+	Write func(ctx context.Context, messageType MessageType, data []byte, next WriteFunc) error
+}
+
+// ChainDialer returns a WebSocketDialer that runs base through middlewares. The first middleware
+// is the outermost. A nil base means DefaultDialer().
+func ChainDialer(base WebSocketDialer, middlewares ...WebSocketMiddleware) WebSocketDialer {
+	if base == nil {
+		base = DefaultDialer()
+	}
+	if len(middlewares) == 0 {
+		return base
+	}
+	return &chainDialer{base: base, middlewares: middlewares}
+}
+
+type chainDialer struct {
+	base        WebSocketDialer
+	middlewares []WebSocketMiddleware
+}
+
+func (d *chainDialer) Dial(ctx context.Context, url string, header http.Header) (WebSocketConn, error) {
+	dial := DialFunc(func(ctx context.Context, url string, header http.Header) (WebSocketConn, error) {
+		conn, err := d.base.Dial(ctx, url, header)
+		if err != nil {
+			return nil, err
+		}
+		return newChainConn(conn, d.middlewares), nil
+	})
+	for i := len(d.middlewares) - 1; i >= 0; i-- {
+		if hook := d.middlewares[i].Dial; hook != nil {
+			next := dial
+			dial = func(ctx context.Context, url string, header http.Header) (WebSocketConn, error) {
+				return hook(ctx, url, header, next)
+			}
+		}
+	}
+	return dial(ctx, url, header)
+}
+
+// chainConn runs reads and writes through the middleware hooks.
+type chainConn struct {
+	WebSocketConn
+	read  ReadFunc
+	write WriteFunc
+}
+
+func newChainConn(conn WebSocketConn, middlewares []WebSocketMiddleware) *chainConn {
+	c := &chainConn{WebSocketConn: conn, read: conn.ReadMessage, write: conn.WriteMessage}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if hook := middlewares[i].Read; hook != nil {
+			next := c.read
+			c.read = func(ctx context.Context) (MessageType, []byte, error) {
+				return hook(ctx, next)
+			}
+		}
+		if hook := middlewares[i].Write; hook != nil {
+			next := c.write
+			c.write = func(ctx context.Context, messageType MessageType, data []byte) error {
+				return hook(ctx, messageType, data, next)
+			}
+		}
+	}
+	return c
+}
+
+func (c *chainConn) ReadMessage(ctx context.Context) (MessageType, []byte, error) {
+	return c.read(ctx)
+}
+
+func (c *chainConn) WriteMessage(ctx context.Context, messageType MessageType, data []byte) error {
+	return c.write(ctx, messageType, data)
+}
@@ -0,0 +1,135 @@
+package transcripts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestClientHTTPMiddlewareAddsHeadersAndRewritesURL(t *testing.T) {
+	t.Parallel()
+
+	var gotHeader, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader, gotPath = r.Header.Get("X-Tenant"), r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":"hello"}`))
+	}))
+	defer server.Close()
+	proxy, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		order   []string
+		wrapped int
+	)
+	audit := func(next http.RoundTripper) http.RoundTripper {
+		wrapped++
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			order = append(order, "audit "+req.URL.Host)
+			return next.RoundTrip(req)
+		})
+	}
+	rewrite := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.URL.Scheme, req.URL.Host, req.Host = proxy.Scheme, proxy.Host, ""
+			req.Header.Set("X-Tenant", "acme")
+			order = append(order, "rewrite")
+			return next.RoundTrip(req)
+		})
+	}
+
+	cfg := DefaultConfig("test-key")
+	cfg.HTTPBaseURL = "https://api.elevenlabs.invalid/v1/speech-to-text"
+	cfg.HTTPMiddleware = []HTTPMiddleware{audit, rewrite}
+	resp, err := NewClientWithConfig(cfg).Transcribe(context.Background(), TranscriptionRequest{
+		ModelID:  "scribe_v1",
+		FileName: "sample.wav",
+		File:     strings.NewReader("audio"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Text, "hello"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if got, want := gotHeader, "acme"; got != want {
+		t.Errorf("X-Tenant = %q, want %q", got, want)
+	}
+	if got, want := gotPath, "/v1/speech-to-text"; got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(order, ","), "audit api.elevenlabs.invalid,rewrite"; got != want {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+	if cfg.HTTPClient.Transport != nil {
+		t.Error("middleware changed the configured HTTP client")
+	}
+	if got, want := wrapped, 1; got != want {
+		t.Errorf("middleware built %d times, want %d", got, want)
+	}
+}
+
+func TestConnectRunsWebSocketMiddleware(t *testing.T) {
+	t.Parallel()
+
+	dialer := &captureDialer{}
+	var order, reads, writes []string
+	proxy := WebSocketMiddleware{
+		Dial: func(ctx context.Context, rawURL string, header http.Header, next DialFunc) (WebSocketConn, error) {
+			order = append(order, "proxy")
+			header.Set("X-Tenant", "acme")
+			return next(ctx, strings.Replace(rawURL, "wss://api.elevenlabs.io", "wss://egress.internal", 1), header)
+		},
+		Write: func(ctx context.Context, messageType MessageType, data []byte, next WriteFunc) error {
+			writes = append(writes, string(data))
+			return next(ctx, messageType, data)
+		},
+	}
+	audit := WebSocketMiddleware{
+		Dial: func(ctx context.Context, rawURL string, header http.Header, next DialFunc) (WebSocketConn, error) {
+			order = append(order, "audit")
+			return next(ctx, rawURL, header)
+		},
+		Read: func(ctx context.Context, next ReadFunc) (MessageType, []byte, error) {
+			messageType, data, err := next(ctx)
+			reads = append(reads, "read")
+			return messageType, data, err
+		},
+	}
+
+	cfg := DefaultConfig("test-key")
+	cfg.WebSocketMiddleware = []WebSocketMiddleware{proxy}
+	conn, err := NewClientWithConfig(cfg).Connect(context.Background(), WithDialer(dialer), WithWebSocketMiddleware(audit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dialer.url, "wss://egress.internal/v1/speech-to-text/realtime?") {
+		t.Errorf("url = %q, want the egress host", dialer.url)
+	}
+	if got, want := dialer.header.Get("X-Tenant"), "acme"; got != want {
+		t.Errorf("X-Tenant = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(order, ","), "proxy,audit"; got != want {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+
+	if err = conn.SendMessageRaw(context.Background(), []byte(`{"message_type":"input_audio_chunk"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = conn.ReadMessageRaw(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(writes) != 1 || len(dialer.conn.writes) != 1 {
+		t.Errorf("writes seen by middleware = %d, by conn = %d, want 1 and 1", len(writes), len(dialer.conn.writes))
+	}
+	if got, want := len(reads), 1; got != want {
+		t.Errorf("reads seen by middleware = %d, want %d", got, want)
+	}
+}
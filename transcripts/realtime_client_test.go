@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 )
 
 type captureDialer struct {
@@ -342,3 +344,69 @@ func TestUnmarshalServerEventSupportsLatestRealtimeSchemas(t *testing.T) {
 		t.Fatalf("len(Words[0].Characters) = %d, want %d", got, want)
 	}
 }
+
+func TestRecognizerFinishWaitsForCommittedTranscripts(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn()
+	wsConn.onWrite = func(data []byte) []string {
+		var msg InputAudioChunkEvent
+		_ = json.Unmarshal(data, &msg)
+		if !msg.Commit {
+			return nil
+		}
+		return []string{
+			`{"message_type":"committed_transcript","text":"hello"}`,
+			`{"message_type":"committed_transcript_with_timestamps","text":"hello"}`,
+		}
+	}
+	var committed []string
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}, sampleRate: 16000},
+		func(_ context.Context, event ServerEvent) {
+			if e, ok := event.(SpeechRecognizedEventArgs); ok {
+				committed = append(committed, e.Text)
+			}
+		})
+	recognizer.Start()
+
+	if err := recognizer.Send([]byte{0, 0}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := recognizer.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := recognizer.Send([]byte{0, 0}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := recognizer.Finish(ctx); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	if err := <-recognizer.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+	if got, want := len(committed), 2; got != want {
+		t.Fatalf("len(committed) = %d, want %d", got, want)
+	}
+}
+
+func TestRecognizerFinishHonorsDeadline(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn()
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}, sampleRate: 16000})
+	recognizer.Start()
+	if err := recognizer.Send([]byte{0, 0}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := recognizer.Finish(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Finish() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if !wsConn.closed {
+		t.Fatal("connection should be closed after Finish")
+	}
+}
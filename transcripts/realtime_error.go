@@ -0,0 +1,132 @@
+package transcripts
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrorClass groups realtime error events by how a client should react to them.
+// ClassifyError maps the documented event types as follows:
+//
+//   - retryable: rate_limited, queue_overflow, resource_exhausted, commit_throttled and
+//     transcriber_error, a transient failure of the transcription backend
+//   - fatal: auth_error, quota_exceeded, unaccepted_terms and session_time_limit_exceeded,
+//     after which the server ends the session
+//   - input: chunk_size_exceeded, input_error, invalid_request and insufficient_audio_activity
+//   - unknown: every other type
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown is used for errors without a documented reaction.
+	ErrorClassUnknown ErrorClass = iota
+	// ErrorClassRetryable errors are transient. Slow down and try again.
+	ErrorClassRetryable
+	// ErrorClassFatal errors end the session. Retrying on the same connection is meaningless.
+	ErrorClassFatal
+	// ErrorClassInput errors are caused by the audio or messages sent by the client.
+	ErrorClassInput
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassRetryable:
+		return "retryable"
+	case ErrorClassFatal:
+		return "fatal"
+	case ErrorClassInput:
+		return "input"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyError returns the class of a realtime error event type.
+func ClassifyError(eventType ServerEventType) ErrorClass {
+	switch eventType {
+	case ServerEventRateLimitedError,
+		ServerEventQueueOverflowError,
+		ServerEventResourceExhaustedError,
+		ServerEventCommitThrottledError,
+		ServerEventTranscriberError:
+		return ErrorClassRetryable
+	case ServerEventAuthError,
+		ServerEventQuotaExceededError,
+		ServerEventUnacceptedTermsError,
+		ServerEventSessionTimeLimitExceededError:
+		return ErrorClassFatal
+	case ServerEventChunkSizeExceededError,
+		ServerEventInputError,
+		ServerEventInvalidRequestError,
+		ServerEventInsufficientAudioActivityError:
+		return ErrorClassInput
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// RealtimeError is a realtime error event as a Go error.
+type RealtimeError struct {
+	Type    ServerEventType
+	Class   ErrorClass
+	Message string
+}
+
+func (e *RealtimeError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("elevenlabs realtime %s error (%s): %s", e.Class, e.Type, e.Message)
+	}
+	return fmt.Sprintf("elevenlabs realtime %s error (%s)", e.Class, e.Type)
+}
+
+// Retryable reports whether the error is transient.
+func (e *RealtimeError) Retryable() bool {
+	return e.Class == ErrorClassRetryable
+}
+
+// AsError returns the event as a *RealtimeError.
+func (r SpeechRecognitionCanceledEventArgs) AsError() *RealtimeError {
+	return &RealtimeError{
+		Type:    r.Type,
+		Class:   ClassifyError(r.Type),
+		Message: r.Error,
+	}
+}
+
+// IsRetryable reports whether err wraps a retryable *RealtimeError.
+func IsRetryable(err error) bool {
+	var realtimeErr *RealtimeError
+	return errors.As(err, &realtimeErr) && realtimeErr.Retryable()
+}
+
+// ErrorAction is what a Recognizer does after an error event.
+type ErrorAction int
+
+const (
+	// ErrorActionContinue keeps reading and sending as usual.
+	ErrorActionContinue ErrorAction = iota
+	// ErrorActionBackoff delays subsequent Send and Commit calls with an exponential backoff.
+	ErrorActionBackoff
+	// ErrorActionStop closes the connection, stops the recognizer and reports the error on Err.
+	ErrorActionStop
+)
+
+// ErrorPolicy decides how a Recognizer reacts to an error event.
+type ErrorPolicy func(err *RealtimeError) ErrorAction
+
+// DefaultErrorPolicy stops on fatal errors, backs off on retryable errors and continues otherwise.
+func DefaultErrorPolicy(err *RealtimeError) ErrorAction {
+	switch err.Class {
+	case ErrorClassFatal:
+		return ErrorActionStop
+	case ErrorClassRetryable:
+		return ErrorActionBackoff
+	default:
+		return ErrorActionContinue
+	}
+}
+
+const (
+	minErrorBackoff = 250 * time.Millisecond
+	maxErrorBackoff = 5 * time.Second
+)
@@ -0,0 +1,96 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClassifyErrorCoversDocumentedErrorTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[ServerEventType]ErrorClass{
+		ServerEventRateLimitedError:       ErrorClassRetryable,
+		ServerEventQueueOverflowError:     ErrorClassRetryable,
+		ServerEventResourceExhaustedError: ErrorClassRetryable,
+		ServerEventCommitThrottledError:   ErrorClassRetryable,
+		ServerEventAuthError:              ErrorClassFatal,
+		ServerEventQuotaExceededError:     ErrorClassFatal,
+		ServerEventUnacceptedTermsError:   ErrorClassFatal,
+		ServerEventChunkSizeExceededError: ErrorClassInput,
+		ServerEventInputError:             ErrorClassInput,
+		ServerEventError:                  ErrorClassUnknown,
+	}
+	for eventType, want := range tests {
+		if got := ClassifyError(eventType); got != want {
+			t.Fatalf("ClassifyError(%s) = %s, want %s", eventType, got, want)
+		}
+	}
+	if !IsRetryable(SpeechRecognitionCanceledEventArgs{
+		RecognitionEventArgs: RecognitionEventArgs{Type: ServerEventQueueOverflowError},
+	}.AsError()) {
+		t.Fatal("queue_overflow should be retryable")
+	}
+}
+
+func TestRecognizerStopsOnFatalError(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn(
+		`{"message_type":"auth_error","error":"invalid key"}`,
+		`{"message_type":"partial_transcript","text":"never delivered"}`,
+	)
+	var seen []ServerEventType
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}},
+		func(_ context.Context, event ServerEvent) {
+			seen = append(seen, event.ServerEventType())
+		})
+	recognizer.Start()
+
+	select {
+	case err := <-recognizer.Err():
+		var realtimeErr *RealtimeError
+		if !errors.As(err, &realtimeErr) {
+			t.Fatalf("Err() = %v, want *RealtimeError", err)
+		}
+		if got, want := realtimeErr.Class, ErrorClassFatal; got != want {
+			t.Fatalf("Class = %s, want %s", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for recognizer to stop")
+	}
+	if got, want := len(seen), 1; got != want {
+		t.Fatalf("len(seen) = %d, want %d", got, want)
+	}
+	wsConn.mu.Lock()
+	defer wsConn.mu.Unlock()
+	if !wsConn.closed {
+		t.Fatal("connection left open after a fatal error")
+	}
+}
+
+func TestRecognizerBacksOffOnRetryableError(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn(`{"message_type":"rate_limited","error":"slow down"}`)
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}})
+	recognizer.Start()
+	defer recognizer.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for recognizer.backoffUntil.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for backoff")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	if err := recognizer.Send([]byte{0, 0}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < minErrorBackoff/2 {
+		t.Fatalf("Send() returned after %v, want backoff", elapsed)
+	}
+}
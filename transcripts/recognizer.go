@@ -4,25 +4,61 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type ServerEventHandler func(ctx context.Context, event ServerEvent)
 
 type Recognizer struct {
-	ctx      context.Context
-	conn     *Conn
-	handlers []ServerEventHandler
-	errCh    chan error
+	ctx          context.Context
+	conn         *Conn
+	handlers     []ServerEventHandler
+	streams      []*EventStream
+	errorPolicy  ErrorPolicy
+	backoff      time.Duration
+	backoffUntil atomic.Int64
+	previousText atomic.Pointer[string]
+	vad          *voiceActivityDetector
+	dispatchMu   sync.Mutex
+	errCh        chan error
+	done         chan struct{}
+
+	// Commit bookkeeping used by Finish. committed is only written by the read goroutine.
+	commits       atomic.Int64
+	uncommitted   atomic.Bool
+	committed     atomic.Int64
+	committedCh   chan struct{}
+	lastCommitted ServerEvent
+	closing       atomic.Bool
+
+	latency   recognizerLatency
+	sessionID atomic.Pointer[string]
 }
 
 // NewRecognizer creates a new Recognizer.
 func NewRecognizer(ctx context.Context, conn *Conn, handlers ...ServerEventHandler) *Recognizer {
 	return &Recognizer{
-		ctx:      ctx,
-		conn:     conn,
-		handlers: handlers,
-		errCh:    make(chan error, 1),
+		ctx:         ctx,
+		conn:        conn,
+		handlers:    handlers,
+		errorPolicy: DefaultErrorPolicy,
+		errCh:       make(chan error, 1),
+		done:        make(chan struct{}),
+		committedCh: make(chan struct{}, 1),
+	}
+}
+
+// SetErrorPolicy sets how the recognizer reacts to error events. It must be called before Start.
+// The default is DefaultErrorPolicy. When the policy returns ErrorActionStop, the recognizer
+// stops reading and Err receives the *RealtimeError.
+func (r *Recognizer) SetErrorPolicy(policy ErrorPolicy) {
+	if policy == nil {
+		policy = DefaultErrorPolicy
 	}
+	r.errorPolicy = policy
 }
 
 // Err returns a channel that receives errors from the ConnHandler.
@@ -37,6 +73,10 @@ func (r *Recognizer) Err() <-chan error {
 func (r *Recognizer) Start() {
 	go func() {
 		err := r.run()
+		close(r.done)
+		for _, stream := range r.streams {
+			stream.close()
+		}
 		if err != nil {
 			r.errCh <- err
 		}
@@ -45,26 +85,107 @@ func (r *Recognizer) Start() {
 }
 
 func (r *Recognizer) Send(pcm []byte) error {
-	return r.conn.SendMessage(r.ctx, InputAudioChunkEvent{
+	if err := r.waitBackoff(); err != nil {
+		return err
+	}
+	event := InputAudioChunkEvent{
 		Audio:      base64.StdEncoding.EncodeToString(pcm),
 		Commit:     false,
 		SampleRate: r.conn.sampleRate,
-	})
+	}
+	if previousText := r.previousText.Swap(nil); previousText != nil {
+		event.PreviousText = *previousText
+	}
+	if err := r.conn.SendMessage(r.ctx, event); err != nil {
+		return err
+	}
+	r.markSent()
+	r.uncommitted.Store(true)
+	if r.vad == nil {
+		return nil
+	}
+	for _, vadEvent := range r.vad.process(pcm) {
+		r.dispatch(vadEvent)
+		if vadEvent.ServerEventType() == ServerEventLocalSpeechEnded {
+			if err := r.Commit(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SetPreviousText sets text context that is sent with the next audio chunk.
+// The server only accepts it alongside the first audio chunk of a session.
+func (r *Recognizer) SetPreviousText(text string) {
+	if text == "" {
+		r.previousText.Store(nil)
+		return
+	}
+	r.previousText.Store(&text)
 }
 
 func (r *Recognizer) Commit() error {
-	return r.conn.SendMessage(r.ctx, InputAudioChunkEvent{
+	if err := r.waitBackoff(); err != nil {
+		return err
+	}
+	if err := r.conn.SendMessage(r.ctx, InputAudioChunkEvent{
 		Commit:     true,
 		SampleRate: r.conn.sampleRate,
-	})
+	}); err != nil {
+		return err
+	}
+	r.uncommitted.Store(false)
+	r.commits.Add(1)
+	r.markCommit()
+	return nil
 }
 
 func (r *Recognizer) Stop() error {
 	return r.conn.Close()
 }
 
+// Finish commits any audio sent since the last commit, waits for the committed transcripts and closes
+// the connection with a normal closure status. The recognizer must have been started.
+//
+// Finish waits until every commit sent on this recognizer has a committed transcript, with or without
+// timestamps. If ctx is done first, the connection is closed anyway and ctx.Err() is returned.
+// After Finish, Err receives no error caused by the close.
+func (r *Recognizer) Finish(ctx context.Context) error {
+	var err error
+	target := r.commits.Load()
+	if r.uncommitted.Load() {
+		committedBefore := r.committed.Load()
+		if err = r.Commit(); err == nil {
+			// With server-side VAD commits, also require one transcript after our own commit.
+			target = max(r.commits.Load(), committedBefore+1)
+		}
+	}
+	if err == nil {
+		err = r.waitCommitted(ctx, target)
+	}
+	r.closing.Store(true)
+	if closeErr := r.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (r *Recognizer) waitCommitted(ctx context.Context, target int64) error {
+	for r.committed.Load() < target {
+		select {
+		case <-r.committedCh:
+		case <-r.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 func (r *Recognizer) run() error {
-	defer r.conn.logger.Debugf("conn handler exited")
+	defer r.log(slog.LevelDebug, "recognizer exited")
 	for {
 		select {
 		case <-r.ctx.Done():
@@ -76,13 +197,116 @@ func (r *Recognizer) run() error {
 		if err != nil {
 			var permanent *PermanentError
 			if errors.As(err, &permanent) {
+				if r.closing.Load() {
+					return nil
+				}
 				return permanent.Err
 			}
-			r.conn.logger.Warnf("read message temporary error: %+v", err)
+			r.log(slog.LevelWarn, "read message temporary error", slog.Any("error", err))
 			continue
 		}
-		for _, handler := range r.handlers {
-			handler(r.ctx, msg)
+		if started, ok := msg.(SessionStartEventArgs); ok && started.SessionID != "" {
+			r.sessionID.Store(&started.SessionID)
+		}
+		r.log(slog.LevelDebug, "websocket event", slog.String("event_type", string(msg.ServerEventType())))
+		r.dispatch(msg)
+		r.observeEvent(msg, r.trackCommitted(msg))
+		for _, stream := range r.streams {
+			if err = stream.Err(); err != nil {
+				return err
+			}
+		}
+		if err = r.applyErrorPolicy(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// log logs through the connection's logger with the session ID once the session started.
+func (r *Recognizer) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if sessionID := r.sessionID.Load(); sessionID != nil {
+		attrs = append(attrs, slog.String("session_id", *sessionID))
+	}
+	LogAttrs(r.ctx, r.conn.logger, level, msg, attrs...)
+}
+
+// dispatch calls the handlers. Local VAD events are dispatched from Send, so calls are serialized.
+func (r *Recognizer) dispatch(event ServerEvent) {
+	r.dispatchMu.Lock()
+	defer r.dispatchMu.Unlock()
+	for _, handler := range r.handlers {
+		handler(r.ctx, event)
+	}
+}
+
+// applyErrorPolicy runs on the read goroutine after the handlers saw msg.
+func (r *Recognizer) applyErrorPolicy(msg ServerEvent) error {
+	switch e := msg.(type) {
+	case SpeechRecognitionCanceledEventArgs:
+		realtimeErr := e.AsError()
+		switch r.errorPolicy(realtimeErr) {
+		case ErrorActionStop:
+			// Close the session so that it is not left open and billed until the server times it out.
+			if err := r.conn.Close(); err != nil {
+				r.log(slog.LevelWarn, "close after error event failed", slog.Any("error", err))
+			}
+			return realtimeErr
+		case ErrorActionBackoff:
+			r.backoff *= 2
+			r.backoff = min(max(r.backoff, minErrorBackoff), maxErrorBackoff)
+			r.backoffUntil.Store(time.Now().Add(r.backoff).UnixNano())
+			r.log(slog.LevelWarn, "backing off after error event",
+				slog.String("event_type", string(e.ServerEventType())), slog.Any("error", realtimeErr), slog.Duration("backoff", r.backoff))
 		}
+	case SpeechRecognizingEventArgs, SpeechRecognizedEventArgs, SpeechRecognizedWithTimestampEventArgs:
+		r.backoff = 0
+	}
+	return nil
+}
+
+// trackCommitted counts completed commits. A committed_transcript and a committed_transcript_with_timestamps
+// for the same text right after each other count once. insufficient_audio_activity completes a commit
+// that had no audio to transcribe. It reports whether msg completed a commit.
+func (r *Recognizer) trackCommitted(msg ServerEvent) bool {
+	text, isTranscript := committedText(msg)
+	if !isTranscript && msg.ServerEventType() != ServerEventInsufficientAudioActivityError {
+		return false
+	}
+	if isTranscript && r.lastCommitted != nil && r.lastCommitted.ServerEventType() != msg.ServerEventType() {
+		if lastText, ok := committedText(r.lastCommitted); ok && lastText == text {
+			r.lastCommitted = nil
+			return false
+		}
+	}
+	r.lastCommitted = msg
+	r.committed.Add(1)
+	select {
+	case r.committedCh <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+func committedText(event ServerEvent) (string, bool) {
+	switch e := event.(type) {
+	case SpeechRecognizedEventArgs:
+		return e.Text, true
+	case SpeechRecognizedWithTimestampEventArgs:
+		return e.Text, true
+	default:
+		return "", false
+	}
+}
+
+func (r *Recognizer) waitBackoff() error {
+	return sleepContext(r.ctx, r.backoffRemaining())
+}
+
+// backoffRemaining returns how long Send and Commit wait before writing, or zero.
+func (r *Recognizer) backoffRemaining() time.Duration {
+	until := r.backoffUntil.Load()
+	if until == 0 {
+		return 0
 	}
+	return time.Until(time.Unix(0, until))
 }
@@ -0,0 +1,397 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRolloverLead is how long before the session limit the next session is opened.
+	DefaultRolloverLead = 15 * time.Second
+	// DefaultHandoffTimeout bounds how long a retiring session may take to deliver its final transcript.
+	DefaultHandoffTimeout = 10 * time.Second
+	// DefaultPreviousTextLength is the number of characters of committed text carried into the next session.
+	DefaultPreviousTextLength = 200
+)
+
+// ErrRollingSessionClosed is returned when using a RollingSession after Finish.
+var ErrRollingSessionClosed = errors.New("rolling session closed")
+
+// ErrSessionLimitRequired is returned by Start when RollingSessionOptions.SessionLimit is not set.
+var ErrSessionLimitRequired = errors.New("rolling session: SessionLimit is required")
+
+// RollingSessionOptions configures a RollingSession.
+type RollingSessionOptions struct {
+	// ConnectOptions are passed to Client.Connect for every session.
+	ConnectOptions []ConnectOption
+	// SessionLimit is the server's session time limit, which depends on the plan and model. It is
+	// required; the server reports reaching it with session_time_limit_exceeded.
+	SessionLimit time.Duration
+	// Lead is how long before SessionLimit the next session is opened. Defaults to DefaultRolloverLead.
+	Lead time.Duration
+	// HandoffTimeout bounds the wait for a retiring session's final transcript. Defaults to DefaultHandoffTimeout.
+	HandoffTimeout time.Duration
+	// PreviousText is sent as context with the first audio chunk of the first session.
+	PreviousText string
+	// PreviousTextLength is the number of trailing characters of committed text sent as context
+	// to each following session. Defaults to DefaultPreviousTextLength.
+	PreviousTextLength int
+}
+
+// RollingSession transcribes an unbounded audio stream over consecutive realtime sessions.
+//
+// Shortly before a session reaches its time limit, the next session is opened and audio is switched
+// to it. The retiring session is committed at that point and finished in the background, so the two
+// sessions overlap at a commit boundary. The tail of the committed transcript known when the first
+// audio chunk goes to the new session is sent as its previous_text.
+//
+// Handlers see one continuous event stream: events of a new session are held back until the previous
+// session delivered its final transcript, word timestamps are shifted by the audio already sent to
+// earlier sessions, and only the first session_started event is delivered.
+type RollingSession struct {
+	client   *Client
+	ctx      context.Context
+	opts     RollingSessionOptions
+	handlers []ServerEventHandler
+
+	mu          sync.Mutex
+	current     *rollingSegment
+	sentBytes   int64
+	bytesPerSec int64
+	rolling     bool
+	closed      bool
+	closedCh    chan struct{}
+	timer       *time.Timer
+	errCh       chan error
+	errClosed   bool
+
+	// Delivery state, only touched by the goroutine currently delivering events.
+	sessionStarted bool
+	lastCommitted  ServerEvent
+	tailMu         sync.Mutex
+	tail           string
+}
+
+type rollingSegment struct {
+	recognizer *Recognizer
+	offset     time.Duration
+	prevDone   <-chan struct{}
+	ready      chan struct{}
+	done       chan struct{}
+	retired    atomic.Bool
+	// sentAudio is guarded by RollingSession.mu.
+	sentAudio bool
+}
+
+// NewRollingSession creates a RollingSession. Call Start to open the first session.
+func (c *Client) NewRollingSession(ctx context.Context, opts RollingSessionOptions, handlers ...ServerEventHandler) *RollingSession {
+	if opts.Lead <= 0 {
+		opts.Lead = DefaultRolloverLead
+	}
+	if opts.SessionLimit > 0 && opts.Lead >= opts.SessionLimit {
+		opts.Lead = opts.SessionLimit / 2
+	}
+	if opts.HandoffTimeout <= 0 {
+		opts.HandoffTimeout = DefaultHandoffTimeout
+	}
+	if opts.PreviousTextLength <= 0 {
+		opts.PreviousTextLength = DefaultPreviousTextLength
+	}
+	return &RollingSession{
+		client:   c,
+		ctx:      ctx,
+		opts:     opts,
+		handlers: handlers,
+		errCh:    make(chan error, 1),
+		closedCh: make(chan struct{}),
+	}
+}
+
+// Start opens the first session. It returns ErrSessionLimitRequired without a SessionLimit.
+func (s *RollingSession) Start() error {
+	if s.opts.SessionLimit <= 0 {
+		return ErrSessionLimitRequired
+	}
+	done := make(chan struct{})
+	close(done)
+	seg, err := s.connect(done)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = seg
+	close(seg.ready)
+	s.bytesPerSec = seg.recognizer.conn.sampleRate * int64(bytesPerSampleForAudioFormat(seg.recognizer.conn.audioFormat))
+	s.timer = time.AfterFunc(s.opts.SessionLimit-s.opts.Lead, func() { s.rollover(nil) })
+	return nil
+}
+
+// Err returns a channel that receives the first error that ended a session unexpectedly.
+// It is closed once Finish returns.
+func (s *RollingSession) Err() <-chan error {
+	return s.errCh
+}
+
+// Send sends audio to the current session.
+func (s *RollingSession) Send(pcm []byte) error {
+	if err := s.lockAfterBackoff(); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	if !s.current.sentAudio {
+		// Use the freshest committed text: the retiring session may still be delivering it.
+		if s.sentBytes == 0 {
+			s.current.recognizer.SetPreviousText(s.opts.PreviousText)
+		} else {
+			s.current.recognizer.SetPreviousText(s.previousText())
+		}
+		s.current.sentAudio = true
+	}
+	if err := s.current.recognizer.Send(pcm); err != nil {
+		return err
+	}
+	s.sentBytes += int64(len(pcm))
+	return nil
+}
+
+// Commit commits the audio sent to the current session.
+func (s *RollingSession) Commit() error {
+	if err := s.lockAfterBackoff(); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	return s.current.recognizer.Commit()
+}
+
+// lockAfterBackoff locks s.mu once the current session is out of its error backoff. It sleeps without
+// the lock, so that rollover and Finish are not held up, and returns early when Finish is called.
+func (s *RollingSession) lockAfterBackoff() error {
+	for {
+		s.mu.Lock()
+		if s.closed || s.current == nil {
+			s.mu.Unlock()
+			return ErrRollingSessionClosed
+		}
+		wait := s.current.recognizer.backoffRemaining()
+		if wait <= 0 {
+			return nil
+		}
+		s.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-s.closedCh:
+			timer.Stop()
+			return ErrRollingSessionClosed
+		case <-s.ctx.Done():
+			timer.Stop()
+			return s.ctx.Err()
+		}
+	}
+}
+
+// Finish finishes the current session like Recognizer.Finish and waits until all events are delivered.
+func (s *RollingSession) Finish(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed || s.current == nil {
+		s.mu.Unlock()
+		return ErrRollingSessionClosed
+	}
+	s.closed = true
+	close(s.closedCh)
+	s.timer.Stop()
+	seg := s.current
+	s.mu.Unlock()
+
+	seg.retired.Store(true)
+	err := seg.recognizer.Finish(ctx)
+	select {
+	case <-seg.done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	s.errClosed = true
+	close(s.errCh)
+	s.mu.Unlock()
+	return err
+}
+
+func (s *RollingSession) connect(prevDone <-chan struct{}) (*rollingSegment, error) {
+	conn, err := s.client.Connect(s.ctx, s.opts.ConnectOptions...)
+	if err != nil {
+		return nil, err
+	}
+	seg := &rollingSegment{
+		prevDone: prevDone,
+		ready:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	seg.recognizer = NewRecognizer(s.ctx, conn, func(ctx context.Context, event ServerEvent) {
+		s.deliver(ctx, seg, event)
+	})
+	seg.recognizer.SetErrorPolicy(func(err *RealtimeError) ErrorAction {
+		if err.Type == ServerEventSessionTimeLimitExceededError {
+			return ErrorActionContinue
+		}
+		return DefaultErrorPolicy(err)
+	})
+	seg.recognizer.Start()
+	go s.watch(seg)
+	return seg, nil
+}
+
+func (s *RollingSession) watch(seg *rollingSegment) {
+	err := <-seg.recognizer.Err()
+	close(seg.done)
+	if err != nil && !seg.retired.Load() {
+		s.report(err)
+	}
+}
+
+func (s *RollingSession) report(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.errClosed {
+		return
+	}
+	select {
+	case s.errCh <- err:
+	default:
+	}
+}
+
+// rollover opens the next session and retires the current one. A non-nil from limits it to
+// retiring from, so that it does nothing once from was replaced.
+func (s *RollingSession) rollover(from *rollingSegment) {
+	s.mu.Lock()
+	if s.rolling || s.closed || s.current == nil || from != nil && from != s.current {
+		s.mu.Unlock()
+		return
+	}
+	s.rolling = true
+	old := s.current
+	s.mu.Unlock()
+
+	next, err := s.connect(old.done)
+	s.mu.Lock()
+	s.rolling = false
+	if err != nil || s.closed {
+		if err == nil {
+			next.retired.Store(true)
+			close(next.ready)
+			_ = next.recognizer.Stop()
+		} else {
+			old.recognizer.log(slog.LevelWarn, "open next realtime session failed", slog.Any("error", err))
+			s.timer.Reset(time.Second)
+		}
+		s.mu.Unlock()
+		return
+	}
+	next.offset = s.sentDuration()
+	close(next.ready)
+	s.current = next
+	s.timer.Reset(s.opts.SessionLimit - s.opts.Lead)
+	s.mu.Unlock()
+
+	old.retired.Store(true)
+	go func() {
+		ctx, cancel := context.WithTimeout(s.ctx, s.opts.HandoffTimeout)
+		defer cancel()
+		_ = old.recognizer.Finish(ctx)
+	}()
+}
+
+// sentDuration must be called with s.mu held.
+func (s *RollingSession) sentDuration() time.Duration {
+	if s.bytesPerSec <= 0 {
+		return 0
+	}
+	return time.Duration(s.sentBytes * int64(time.Second) / s.bytesPerSec)
+}
+
+func (s *RollingSession) deliver(ctx context.Context, seg *rollingSegment, event ServerEvent) {
+	for _, wait := range []<-chan struct{}{seg.ready, seg.prevDone} {
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	switch e := event.(type) {
+	case SessionStartEventArgs:
+		if s.sessionStarted {
+			return
+		}
+		s.sessionStarted = true
+	case SpeechRecognitionCanceledEventArgs:
+		if e.Type == ServerEventSessionTimeLimitExceededError {
+			// A retired session reaching the limit while it finishes needs no new session.
+			if !seg.retired.Swap(true) {
+				go s.rollover(seg)
+			}
+			return
+		}
+	case SpeechRecognizedEventArgs:
+		s.recordCommitted(e, e.Text)
+	case SpeechRecognizedWithTimestampEventArgs:
+		s.recordCommitted(e, e.Text)
+		if seg.offset > 0 {
+			offset := seg.offset.Seconds()
+			words := make([]RealtimeTranscriptWord, len(e.Words))
+			for i, word := range e.Words {
+				word.Start += offset
+				word.End += offset
+				words[i] = word
+			}
+			e.Words = words
+			event = e
+		}
+	}
+	for _, handler := range s.handlers {
+		handler(ctx, event)
+	}
+}
+
+// recordCommitted keeps the tail of the committed transcript, counting paired
+// committed_transcript and committed_transcript_with_timestamps events once.
+func (s *RollingSession) recordCommitted(event ServerEvent, text string) {
+	if last := s.lastCommitted; last != nil && last.ServerEventType() != event.ServerEventType() {
+		if lastText, _ := committedText(last); lastText == text {
+			s.lastCommitted = nil
+			return
+		}
+	}
+	s.lastCommitted = event
+
+	s.tailMu.Lock()
+	defer s.tailMu.Unlock()
+	if text == "" {
+		return
+	}
+	tail := s.tail
+	if tail != "" {
+		tail += " "
+	}
+	tail += text
+	if runes := []rune(tail); len(runes) > s.opts.PreviousTextLength {
+		tail = string(runes[len(runes)-s.opts.PreviousTextLength:])
+	}
+	s.tail = tail
+}
+
+func (s *RollingSession) previousText() string {
+	s.tailMu.Lock()
+	defer s.tailMu.Unlock()
+	return s.tail
+}
@@ -0,0 +1,213 @@
+package transcripts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type rollingDialer struct {
+	mu    sync.Mutex
+	conns []*scriptedWebSocketConn
+}
+
+func (d *rollingDialer) Dial(context.Context, string, http.Header) (WebSocketConn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	session := len(d.conns) + 1
+	conn := newScriptedWebSocketConn(fmt.Sprintf(`{"message_type":"session_started","session_id":"sess_%d"}`, session))
+	conn.onWrite = func(data []byte) []string {
+		var msg InputAudioChunkEvent
+		_ = json.Unmarshal(data, &msg)
+		if !msg.Commit {
+			return nil
+		}
+		return []string{fmt.Sprintf(
+			`{"message_type":"committed_transcript_with_timestamps","text":"part %d","words":[{"text":"part","start":0,"end":0.01}]}`,
+			session,
+		)}
+	}
+	d.conns = append(d.conns, conn)
+	return conn, nil
+}
+
+func (d *rollingDialer) dialed() []*scriptedWebSocketConn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*scriptedWebSocketConn(nil), d.conns...)
+}
+
+func TestRollingSessionHandsOverToNextSession(t *testing.T) {
+	t.Parallel()
+
+	dialer := &rollingDialer{}
+	var (
+		mu       sync.Mutex
+		sessions []string
+		words    []RealtimeTranscriptWord
+		texts    []string
+	)
+	session := NewClient("test-key").NewRollingSession(context.Background(), RollingSessionOptions{
+		ConnectOptions: []ConnectOption{WithDialer(dialer)},
+		SessionLimit:   150 * time.Millisecond,
+		Lead:           50 * time.Millisecond,
+	}, func(_ context.Context, event ServerEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e := event.(type) {
+		case SessionStartEventArgs:
+			sessions = append(sessions, e.SessionID)
+		case SpeechRecognizedWithTimestampEventArgs:
+			texts = append(texts, e.Text)
+			words = append(words, e.Words...)
+		}
+	})
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// 500ms of 16kHz PCM goes to the first session.
+	if err := session.Send(make([]byte, 16000)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := session.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(dialer.dialed()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for rollover")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Wait for the switch to the new session before sending more audio.
+	for {
+		session.mu.Lock()
+		switched := session.current.recognizer.conn.conn == dialer.dialed()[1]
+		session.mu.Unlock()
+		if switched {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for switch")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := session.Send(make([]byte, 320)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := session.Finish(ctx); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	second := dialer.dialed()[1].written()
+	var first InputAudioChunkEvent
+	if err := json.Unmarshal(second[0], &first); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got, want := first.PreviousText, "part 1"; got != want {
+		t.Fatalf("PreviousText = %q, want %q", got, want)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := len(sessions), 1; got != want {
+		t.Fatalf("len(sessions) = %d, want %d", got, want)
+	}
+	if got, want := fmt.Sprint(texts), "[part 1 part 2]"; got != want {
+		t.Fatalf("texts = %s, want %s", got, want)
+	}
+	if got, want := words[1].Start, 0.5; got != want {
+		t.Fatalf("words[1].Start = %v, want %v", got, want)
+	}
+}
+
+func TestRollingSessionRollsOverOnlyFromTheCurrentSession(t *testing.T) {
+	t.Parallel()
+
+	if err := NewClient("test-key").NewRollingSession(context.Background(), RollingSessionOptions{}).Start(); err != ErrSessionLimitRequired {
+		t.Fatalf("Start() without SessionLimit error = %v, want %v", err, ErrSessionLimitRequired)
+	}
+
+	dialer := &rollingDialer{}
+	session := NewClient("test-key").NewRollingSession(context.Background(), RollingSessionOptions{
+		ConnectOptions: []ConnectOption{WithDialer(dialer)},
+		SessionLimit:   time.Hour,
+	})
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	limit := SpeechRecognitionCanceledEventArgs{RecognitionEventArgs: RecognitionEventArgs{Type: ServerEventSessionTimeLimitExceededError}}
+
+	closed := make(chan struct{})
+	close(closed)
+	retired := &rollingSegment{ready: closed, prevDone: closed}
+	retired.retired.Store(true)
+	session.deliver(context.Background(), retired, limit)
+	time.Sleep(20 * time.Millisecond)
+	if got, want := len(dialer.dialed()), 1; got != want {
+		t.Fatalf("sessions after a retired session's limit = %d, want %d", got, want)
+	}
+
+	session.mu.Lock()
+	current := session.current
+	session.mu.Unlock()
+	session.deliver(context.Background(), current, limit)
+	deadline := time.Now().Add(time.Second)
+	for len(dialer.dialed()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for rollover")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := session.Finish(ctx); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+}
+
+func TestRollingSessionSendDoesNotHoldLockDuringBackoff(t *testing.T) {
+	t.Parallel()
+
+	session := NewClient("test-key").NewRollingSession(context.Background(), RollingSessionOptions{
+		ConnectOptions: []ConnectOption{WithDialer(&rollingDialer{})},
+		SessionLimit:   time.Minute,
+	})
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	session.mu.Lock()
+	session.current.recognizer.backoffUntil.Store(time.Now().Add(time.Minute).UnixNano())
+	session.mu.Unlock()
+
+	sendErr := make(chan error, 1)
+	go func() { sendErr <- session.Send(make([]byte, 320)) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// Finish takes the lock and wakes the sleeping Send.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	finished := make(chan struct{})
+	go func() {
+		_ = session.Finish(ctx)
+		close(finished)
+	}()
+	select {
+	case err := <-sendErr:
+		if err != ErrRollingSessionClosed {
+			t.Errorf("Send() error = %v, want %v", err, ErrRollingSessionClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Send() still sleeping after Finish")
+	}
+	<-finished
+}
@@ -0,0 +1,213 @@
+package transcripts
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/internal/g711"
+)
+
+const (
+	// ServerEventLocalSpeechStarted is emitted by the local voice activity detector, not by the server.
+	ServerEventLocalSpeechStarted ServerEventType = "local_speech_started"
+	// ServerEventLocalSpeechEnded is emitted by the local voice activity detector, not by the server.
+	ServerEventLocalSpeechEnded ServerEventType = "local_speech_ended"
+)
+
+const (
+	defaultLocalVADThreshold        = 0.4
+	defaultLocalVADMinSpeechMs      = 250
+	defaultLocalVADMinSilenceMs     = 2500
+	localVADFrameDuration           = 20 * time.Millisecond
+	localVADMinEnergy               = 0.003
+	localVADUnvoicedZeroCrossRate   = 0.25
+	localVADNoiseFloorAdaptionShare = 0.05
+	localVADNoiseFloorFrames        = 10
+)
+
+// LocalSpeechStartedEventArgs reports the start of an utterance detected locally.
+type LocalSpeechStartedEventArgs struct {
+	RecognitionEventArgs
+	// Offset is the position of the speech onset in the audio sent so far.
+	Offset time.Duration
+}
+
+// LocalSpeechEndedEventArgs reports the end of an utterance detected locally.
+type LocalSpeechEndedEventArgs struct {
+	RecognitionEventArgs
+	// Offset is the position where the trailing silence began in the audio sent so far.
+	Offset time.Duration
+	// Duration is the length of the utterance.
+	Duration time.Duration
+}
+
+// EnableLocalVAD runs an energy and zero-crossing voice activity detector on the audio passed to Send.
+// It uses VadThreshold, MinSpeechDurationMs and MinSilenceDurationMs from cfg, with the server defaults
+// for unset fields. At the end of each utterance the recognizer commits automatically, which is meant
+// for CommitStrategyManual sessions.
+//
+// LocalSpeechStartedEventArgs and LocalSpeechEndedEventArgs are delivered to the recognizer's handlers
+// from the goroutine calling Send. Handlers are never called concurrently.
+// It must be called before Start.
+func (r *Recognizer) EnableLocalVAD(cfg RealtimeConfig) {
+	threshold := defaultLocalVADThreshold
+	if cfg.VadThreshold != nil {
+		threshold = *cfg.VadThreshold
+	}
+	minSpeechMs := defaultLocalVADMinSpeechMs
+	if cfg.MinSpeechDurationMs != nil {
+		minSpeechMs = *cfg.MinSpeechDurationMs
+	}
+	minSilenceMs := defaultLocalVADMinSilenceMs
+	if cfg.MinSilenceDurationMs != nil {
+		minSilenceMs = *cfg.MinSilenceDurationMs
+	}
+	sampleRate := r.conn.sampleRate
+	if sampleRate <= 0 {
+		sampleRate = 16000
+	}
+	r.vad = newVoiceActivityDetector(sampleRate, r.conn.audioFormat == AudioFormatUlaw_8000, threshold,
+		time.Duration(minSpeechMs)*time.Millisecond, time.Duration(minSilenceMs)*time.Millisecond)
+}
+
+type voiceActivityDetector struct {
+	sampleRate   int64
+	ulaw         bool
+	frameSamples int
+	// factor is how far above the noise floor a frame must be to count as speech.
+	factor           float64
+	minSpeechFrames  int
+	minSilenceFrames int
+	// warmupFrames is how many frames are judged against localVADMinEnergy while the noise floor is
+	// seeded with the quietest of them.
+	warmupFrames int
+
+	frames     int
+	noiseFloor float64
+	pending    []byte
+	samples    int64
+	speaking   bool
+	run        int
+	runStart   int64
+	speechFrom int64
+}
+
+func newVoiceActivityDetector(sampleRate int64, ulaw bool, threshold float64, minSpeech, minSilence time.Duration) *voiceActivityDetector {
+	frames := func(d time.Duration) int {
+		return max(1, int(d/localVADFrameDuration))
+	}
+	return &voiceActivityDetector{
+		sampleRate:       sampleRate,
+		ulaw:             ulaw,
+		frameSamples:     int(sampleRate * int64(localVADFrameDuration) / int64(time.Second)),
+		factor:           1 + 10*threshold,
+		minSpeechFrames:  frames(minSpeech),
+		minSilenceFrames: frames(minSilence),
+		warmupFrames:     max(localVADNoiseFloorFrames, frames(minSpeech)),
+	}
+}
+
+// process analyses the next piece of audio and returns the local events it triggers.
+func (v *voiceActivityDetector) process(audio []byte) []ServerEvent {
+	v.pending = append(v.pending, audio...)
+	frameBytes := v.frameSamples * 2
+	if v.ulaw {
+		frameBytes = v.frameSamples
+	}
+
+	var events []ServerEvent
+	for len(v.pending) >= frameBytes {
+		frame := v.pending[:frameBytes]
+		v.pending = v.pending[frameBytes:]
+		frameStart := v.samples
+		v.samples += int64(v.frameSamples)
+		if event := v.step(v.isSpeech(frame), frameStart); event != nil {
+			events = append(events, event)
+		}
+	}
+	v.pending = append([]byte(nil), v.pending...)
+	return events
+}
+
+func (v *voiceActivityDetector) step(speech bool, frameStart int64) ServerEvent {
+	if speech == v.speaking {
+		v.run = 0
+		return nil
+	}
+	if v.run == 0 {
+		v.runStart = frameStart
+	}
+	v.run++
+	if !v.speaking && v.run >= v.minSpeechFrames {
+		v.speaking = true
+		v.run = 0
+		v.speechFrom = v.runStart
+		return LocalSpeechStartedEventArgs{
+			RecognitionEventArgs: RecognitionEventArgs{Type: ServerEventLocalSpeechStarted},
+			Offset:               v.duration(v.runStart),
+		}
+	}
+	if v.speaking && v.run >= v.minSilenceFrames {
+		v.speaking = false
+		v.run = 0
+		return LocalSpeechEndedEventArgs{
+			RecognitionEventArgs: RecognitionEventArgs{Type: ServerEventLocalSpeechEnded},
+			Offset:               v.duration(v.runStart),
+			Duration:             v.duration(v.runStart - v.speechFrom),
+		}
+	}
+	return nil
+}
+
+func (v *voiceActivityDetector) isSpeech(frame []byte) bool {
+	var (
+		energy    float64
+		crossings int
+		previous  float64
+	)
+	for i := 0; i < v.frameSamples; i++ {
+		var sample float64
+		if v.ulaw {
+			sample = float64(g711.MulawDecode(frame[i])) / 32768
+		} else {
+			sample = float64(int16(binary.LittleEndian.Uint16(frame[2*i:]))) / 32768
+		}
+		energy += sample * sample
+		if i > 0 && (sample >= 0) != (previous >= 0) {
+			crossings++
+		}
+		previous = sample
+	}
+	rms := math.Sqrt(energy / float64(v.frameSamples))
+	zeroCrossRate := float64(crossings) / float64(max(1, v.frameSamples-1))
+
+	// The stream may start mid-speech, so the first frames are judged against the minimum level and
+	// the noise floor is seeded with the quietest of them.
+	level := localVADMinEnergy
+	if v.frames >= v.warmupFrames {
+		level = max(v.noiseFloor*v.factor, localVADMinEnergy)
+	}
+	speech := rms > level || (rms > level/2 && zeroCrossRate > localVADUnvoicedZeroCrossRate)
+	if v.frames < v.warmupFrames {
+		if v.frames == 0 || rms < v.noiseFloor {
+			v.noiseFloor = rms
+		}
+		v.frames++
+		if v.frames == v.warmupFrames {
+			v.noiseFloor = max(v.noiseFloor, localVADMinEnergy/v.factor)
+		}
+		return speech
+	}
+	switch {
+	case rms < v.noiseFloor:
+		v.noiseFloor = max(rms, localVADMinEnergy/v.factor)
+	case !speech:
+		v.noiseFloor += (rms - v.noiseFloor) * localVADNoiseFloorAdaptionShare
+	}
+	return speech
+}
+
+func (v *voiceActivityDetector) duration(samples int64) time.Duration {
+	return time.Duration(samples * int64(time.Second) / v.sampleRate)
+}
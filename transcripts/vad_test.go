@@ -0,0 +1,129 @@
+package transcripts
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func pcmTone(d time.Duration, sampleRate int, amplitude float64) []byte {
+	n := int(int64(sampleRate) * int64(d) / int64(time.Second))
+	out := make([]byte, 2*n)
+	for i := 0; i < n; i++ {
+		sample := amplitude * math.Sin(2*math.Pi*220*float64(i)/float64(sampleRate))
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(sample*32767)))
+	}
+	return out
+}
+
+func TestRecognizerLocalVADCommitsAtUtteranceEnd(t *testing.T) {
+	t.Parallel()
+
+	wsConn := newScriptedWebSocketConn()
+	var (
+		mu     sync.Mutex
+		events []ServerEvent
+	)
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: wsConn, logger: NopLogger{}, sampleRate: 16000},
+		func(_ context.Context, event ServerEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		})
+	minSilence := 300
+	recognizer.EnableLocalVAD(RealtimeConfig{MinSilenceDurationMs: &minSilence})
+	recognizer.Start()
+	defer func() { _ = recognizer.Stop() }()
+
+	audio := pcmTone(500*time.Millisecond, 16000, 0.0005)
+	audio = append(audio, pcmTone(time.Second, 16000, 0.5)...)
+	audio = append(audio, pcmTone(500*time.Millisecond, 16000, 0.0005)...)
+	// Send in odd-sized chunks so that frames straddle calls.
+	for len(audio) > 0 {
+		n := min(len(audio), 1234)
+		if err := recognizer.Send(audio[:n]); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		audio = audio[n:]
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := len(events), 2; got != want {
+		t.Fatalf("len(events) = %d, want %d: %+v", got, want, events)
+	}
+	started, ok := events[0].(LocalSpeechStartedEventArgs)
+	if !ok {
+		t.Fatalf("events[0] = %T, want LocalSpeechStartedEventArgs", events[0])
+	}
+	if got, want := started.Offset, 500*time.Millisecond; got != want {
+		t.Errorf("start Offset = %v, want %v", got, want)
+	}
+	ended, ok := events[1].(LocalSpeechEndedEventArgs)
+	if !ok {
+		t.Fatalf("events[1] = %T, want LocalSpeechEndedEventArgs", events[1])
+	}
+	if got, want := ended.Duration, time.Second; got != want {
+		t.Errorf("Duration = %v, want %v", got, want)
+	}
+
+	var commits int
+	for _, data := range wsConn.written() {
+		var msg struct {
+			Commit bool `json:"commit"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if msg.Commit {
+			commits++
+		}
+	}
+	if got, want := commits, 1; got != want {
+		t.Errorf("commits = %d, want %d", got, want)
+	}
+}
+
+func TestRecognizerLocalVADDetectsSpeechAtStreamStart(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		events []ServerEvent
+	)
+	recognizer := NewRecognizer(context.Background(), &Conn{conn: newScriptedWebSocketConn(), logger: NopLogger{}, sampleRate: 16000},
+		func(_ context.Context, event ServerEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		})
+	minSilence := 300
+	recognizer.EnableLocalVAD(RealtimeConfig{MinSilenceDurationMs: &minSilence})
+	recognizer.Start()
+	defer func() { _ = recognizer.Stop() }()
+
+	// The stream starts mid-utterance, with a short pause before the rest of it.
+	audio := pcmTone(300*time.Millisecond, 16000, 0.5)
+	audio = append(audio, pcmTone(40*time.Millisecond, 16000, 0.0005)...)
+	audio = append(audio, pcmTone(660*time.Millisecond, 16000, 0.5)...)
+	audio = append(audio, pcmTone(500*time.Millisecond, 16000, 0.0005)...)
+	if err := recognizer.Send(audio); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := len(events), 2; got != want {
+		t.Fatalf("len(events) = %d, want %d: %+v", got, want, events)
+	}
+	if started, ok := events[0].(LocalSpeechStartedEventArgs); !ok || started.Offset != 0 {
+		t.Errorf("events[0] = %+v, want LocalSpeechStartedEventArgs at offset 0", events[0])
+	}
+	if ended, ok := events[1].(LocalSpeechEndedEventArgs); !ok || ended.Duration != time.Second {
+		t.Errorf("events[1] = %+v, want LocalSpeechEndedEventArgs lasting 1s", events[1])
+	}
+}
@@ -0,0 +1,117 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// AudioReaderOptions configures an AudioReader.
+type AudioReaderOptions struct {
+	// BufferSize is the number of audio chunks buffered before the streamer stops reading
+	// from the websocket. Defaults to 16.
+	BufferSize int
+}
+
+// AudioReader is an io.ReadCloser of the audio received by a Streamer, with the chunks concatenated.
+// When the buffer is full the streamer waits for the reader, which in turn slows down the websocket.
+// Read returns io.EOF after the final event, the stream error if the session failed, and an
+// ErrorEvent once the server reported an error.
+type AudioReader struct {
+	chunks chan audioReaderItem
+	closed chan struct{}
+	done   chan struct{}
+
+	closeOnce sync.Once
+	err       error // set before done is closed
+
+	pending []byte
+	readErr error
+}
+
+type audioReaderItem struct {
+	audio []byte
+	err   error
+}
+
+// AudioReader returns an AudioReader fed by this streamer.
+// It must be called before Start.
+func (s *Streamer) AudioReader(opts AudioReaderOptions) *AudioReader {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 16
+	}
+	r := &AudioReader{
+		chunks: make(chan audioReaderItem, opts.BufferSize),
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.handlers = append(s.handlers, r.handle)
+	s.readers = append(s.readers, r)
+	return r
+}
+
+// Read reads audio. It blocks until audio arrives or the stream ends.
+func (r *AudioReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.readErr != nil {
+			return 0, r.readErr
+		}
+		select {
+		case <-r.closed:
+			return 0, io.ErrClosedPipe
+		default:
+		}
+		var item audioReaderItem
+		select {
+		case item = <-r.chunks:
+		case <-r.closed:
+			return 0, io.ErrClosedPipe
+		case <-r.done:
+			// Events delivered before the stream ended come first.
+			select {
+			case item = <-r.chunks:
+			default:
+				item.err = r.err
+			}
+		}
+		r.pending, r.readErr = item.audio, item.err
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Close stops the reader. Audio received afterwards is dropped.
+func (r *AudioReader) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return nil
+}
+
+func (r *AudioReader) handle(ctx context.Context, event StreamEvent) {
+	var item audioReaderItem
+	switch e := event.(type) {
+	case AudioEvent:
+		if len(e.Audio) == 0 {
+			return
+		}
+		item.audio = e.Audio
+	case ErrorEvent:
+		item.err = e
+	default:
+		return
+	}
+	select {
+	case r.chunks <- item:
+	case <-r.closed:
+	case <-ctx.Done():
+	}
+}
+
+// finish is called once the streamer stops; err is nil after a normal end.
+func (r *AudioReader) finish(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+	r.err = err
+	close(r.done)
+}
@@ -0,0 +1,100 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coder/websocket"
+)
+
+func newScriptedRealtimeServer(t *testing.T, replies ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		ctx := r.Context()
+		if _, _, err = conn.Read(ctx); err != nil {
+			t.Errorf("read init message: %v", err)
+			return
+		}
+		for _, reply := range replies {
+			if err = conn.Write(ctx, websocket.MessageText, []byte(reply)); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func connectTestStreamer(t *testing.T, server *httptest.Server) *Streamer {
+	t.Helper()
+	cfg := DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	conn, err := NewClientWithConfig(cfg).ConnectRealtime(context.Background(), StreamInputRequest{VoiceID: "voice_123"})
+	if err != nil {
+		t.Fatalf("ConnectRealtime() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewRealtimeSynthesizer(context.Background(), conn)
+}
+
+func TestAudioReaderConcatenatesAudioUntilFinal(t *testing.T) {
+	t.Parallel()
+
+	server := newScriptedRealtimeServer(t,
+		`{"audio":"YWJj"}`,
+		`{"audio":"ZGVm"}`,
+		`{"isFinal":true}`,
+	)
+	streamer := connectTestStreamer(t, server)
+	reader := streamer.AudioReader(AudioReaderOptions{BufferSize: 1})
+	streamer.Start()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "abcdef"; string(got) != want {
+		t.Errorf("audio = %q, want %q", got, want)
+	}
+	if err = <-streamer.Err(); err != nil {
+		t.Errorf("streamer error = %v", err)
+	}
+}
+
+func TestAudioReaderReturnsServerErrorAfterBufferedAudio(t *testing.T) {
+	t.Parallel()
+
+	server := newScriptedRealtimeServer(t,
+		`{"audio":"YWJj"}`,
+		`{"error":"quota exceeded"}`,
+	)
+	streamer := connectTestStreamer(t, server)
+	reader := streamer.AudioReader(AudioReaderOptions{})
+	streamer.Start()
+
+	got, err := io.ReadAll(reader)
+	if want := "abc"; string(got) != want {
+		t.Errorf("audio = %q, want %q", got, want)
+	}
+	var event ErrorEvent
+	if !errors.As(err, &event) || event.Message != "quota exceeded" {
+		t.Fatalf("ReadAll() error = %v, want ErrorEvent", err)
+	}
+	if err = reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = reader.Read(make([]byte, 1)); err == nil {
+		t.Error("Read() after Close() succeeded")
+	}
+}
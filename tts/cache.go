@@ -0,0 +1,181 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// CacheEntry is synthesized audio stored in a Cache.
+type CacheEntry struct {
+	Audio          []byte    `json:"-"`
+	ContentType    string    `json:"content_type,omitempty"`
+	RequestID      string    `json:"request_id,omitempty"`
+	CharacterCount string    `json:"character_count,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Cache stores synthesized audio by CacheKey. Implementations must be safe for concurrent use.
+// Get returns a nil entry and no error on a miss. Callers must not modify returned entries.
+type Cache interface {
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	Set(ctx context.Context, key string, entry *CacheEntry) error
+	Delete(ctx context.Context, key string) error
+}
+
+// cacheKeyVersion changes whenever the key material changes, so old entries are never misread.
+const cacheKeyVersion = "v1"
+
+// CacheKey returns a stable hash of the request fields that determine the audio: voice, model, text,
+// language, voice settings, output format, seed and pronunciation dictionaries.
+// Stitching context such as PreviousText is not part of the key.
+func CacheKey(req SynthesisRequest) string {
+	material, _ := json.Marshal(struct {
+		Version      string                           `json:"v"`
+		VoiceID      string                           `json:"voice_id"`
+		ModelID      string                           `json:"model_id"`
+		Text         string                           `json:"text"`
+		LanguageCode string                           `json:"language_code"`
+		Settings     *VoiceSettings                   `json:"voice_settings"`
+		OutputFormat AudioFormat                      `json:"output_format"`
+		Seed         *int                             `json:"seed"`
+		Dictionaries []PronunciationDictionaryLocator `json:"dictionaries"`
+	}{
+		Version:      cacheKeyVersion,
+		VoiceID:      req.VoiceID,
+		ModelID:      req.ModelID,
+		Text:         req.Text,
+		LanguageCode: req.LanguageCode,
+		Settings:     req.VoiceSettings,
+		OutputFormat: req.OutputFormat,
+		Seed:         req.Seed,
+		Dictionaries: req.PronunciationDictionaryLocators,
+	})
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:])
+}
+
+// CachedClientOptions configures a CachedClient.
+type CachedClientOptions struct {
+	// OnError is called with cache errors. Cache errors never fail a request; a failing Get is a miss.
+	OnError func(err error)
+}
+
+// CachedClient serves Synthesize and StreamAudio from a Cache and fills it on misses.
+// Failed requests are not cached.
+type CachedClient struct {
+	client *Client
+	cache  Cache
+	opts   CachedClientOptions
+}
+
+// NewCachedClient wraps client with cache.
+func NewCachedClient(client *Client, cache Cache, opts CachedClientOptions) *CachedClient {
+	return &CachedClient{client: client, cache: cache, opts: opts}
+}
+
+// Synthesize returns cached audio for req, or synthesizes and caches it.
+// The cache and the caller get separate copies of the audio, so either may modify theirs.
+func (c *CachedClient) Synthesize(ctx context.Context, req SynthesisRequest) (*SynthesisResponse, error) {
+	key := CacheKey(req)
+	if entry := c.get(ctx, key); entry != nil {
+		return &SynthesisResponse{
+			Audio:          bytes.Clone(entry.Audio),
+			ContentType:    entry.ContentType,
+			RequestID:      entry.RequestID,
+			CharacterCount: entry.CharacterCount,
+			Cached:         true,
+		}, nil
+	}
+	resp, err := c.client.Synthesize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.set(ctx, key, &CacheEntry{
+		Audio:          bytes.Clone(resp.Audio),
+		ContentType:    resp.ContentType,
+		RequestID:      resp.RequestID,
+		CharacterCount: resp.CharacterCount,
+	})
+	return resp, nil
+}
+
+// StreamAudio returns cached audio for req as a stream, or streams from the API while teeing the audio into
+// the cache. The audio is stored only once the body has been read to the end; closing it early stores nothing.
+func (c *CachedClient) StreamAudio(ctx context.Context, req SynthesisRequest) (*StreamResponse, error) {
+	key := CacheKey(req)
+	if entry := c.get(ctx, key); entry != nil {
+		return &StreamResponse{
+			Audio:          io.NopCloser(bytes.NewReader(entry.Audio)),
+			ContentType:    entry.ContentType,
+			RequestID:      entry.RequestID,
+			CharacterCount: entry.CharacterCount,
+			Cached:         true,
+		}, nil
+	}
+	resp, err := c.client.StreamAudio(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Audio = &cacheTee{
+		body: resp.Audio,
+		store: func(audio []byte) {
+			c.set(context.WithoutCancel(ctx), key, &CacheEntry{
+				Audio:          audio,
+				ContentType:    resp.ContentType,
+				RequestID:      resp.RequestID,
+				CharacterCount: resp.CharacterCount,
+			})
+		},
+	}
+	return resp, nil
+}
+
+func (c *CachedClient) get(ctx context.Context, key string) *CacheEntry {
+	entry, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.reportError(err)
+		return nil
+	}
+	return entry
+}
+
+func (c *CachedClient) set(ctx context.Context, key string, entry *CacheEntry) {
+	entry.CreatedAt = time.Now()
+	if err := c.cache.Set(ctx, key, entry); err != nil {
+		c.reportError(err)
+	}
+}
+
+func (c *CachedClient) reportError(err error) {
+	if c.opts.OnError != nil {
+		c.opts.OnError(err)
+	}
+}
+
+// cacheTee copies a response body while it is read and stores it at EOF.
+type cacheTee struct {
+	body  io.ReadCloser
+	buf   bytes.Buffer
+	store func(audio []byte)
+	done  bool
+}
+
+func (t *cacheTee) Read(p []byte) (int, error) {
+	n, err := t.body.Read(p)
+	t.buf.Write(p[:n])
+	if errors.Is(err, io.EOF) && !t.done {
+		t.done = true
+		t.store(t.buf.Bytes())
+	}
+	return n, err
+}
+
+func (t *cacheTee) Close() error {
+	return t.body.Close()
+}
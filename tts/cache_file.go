@@ -0,0 +1,237 @@
+package tts
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileCacheAudioExt = ".audio"
+	fileCacheMetaExt  = ".json"
+)
+
+// FileCacheOptions configures a FileCache. Zero values mean no limit.
+type FileCacheOptions struct {
+	// MaxBytes bounds the total audio size on disk.
+	MaxBytes int64
+	// TTL is how long an entry stays valid after it was stored.
+	TTL time.Duration
+}
+
+// FileCache is a Cache in a directory. Each entry is an audio file plus a JSON metadata file, written
+// atomically. The LRU order and total size are kept in memory, loaded from the directory when it is
+// opened; reads also refresh the metadata file's modification time, so the order survives a restart.
+// Several processes may share a directory; each enforces size limits on the entries it opened, wrote
+// or read.
+type FileCache struct {
+	dir  string
+	opts FileCacheOptions
+	now  func() time.Time
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+	size  int64
+}
+
+type fileCacheItem struct {
+	key  string
+	size int64
+}
+
+// NewFileCache creates a FileCache in dir, creating the directory if needed.
+func NewFileCache(dir string, opts FileCacheOptions) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	c := &FileCache{
+		dir:   dir,
+		opts:  opts,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load indexes the entries already in the directory, least recently used first.
+func (c *FileCache) load() error {
+	type cached struct {
+		key  string
+		used time.Time
+		size int64
+	}
+	metas, err := filepath.Glob(filepath.Join(c.dir, "*"+fileCacheMetaExt))
+	if err != nil {
+		return err
+	}
+	var entries []cached
+	for _, meta := range metas {
+		info, err := os.Stat(meta)
+		if err != nil {
+			continue
+		}
+		key := strings.TrimSuffix(filepath.Base(meta), fileCacheMetaExt)
+		audio, err := os.Stat(c.path(key, fileCacheAudioExt))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, cached{key: key, used: info.ModTime(), size: audio.Size()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].used.Before(entries[j].used) })
+	for _, entry := range entries {
+		c.touch(entry.key, entry.size)
+	}
+	return nil
+}
+
+// Get returns the entry for key.
+func (c *FileCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	if err := validCacheKey(key); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	meta, err := os.ReadFile(c.path(key, fileCacheMetaExt))
+	if errors.Is(err, fs.ErrNotExist) {
+		c.forget(key)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry CacheEntry
+	if err = json.Unmarshal(meta, &entry); err != nil {
+		return nil, fmt.Errorf("read cache entry %s: %w", key, err)
+	}
+	now := c.now()
+	if c.opts.TTL > 0 && now.Sub(entry.CreatedAt) > c.opts.TTL {
+		return nil, c.remove(key)
+	}
+	if entry.Audio, err = os.ReadFile(c.path(key, fileCacheAudioExt)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			c.forget(key)
+			return nil, nil
+		}
+		return nil, err
+	}
+	_ = os.Chtimes(c.path(key, fileCacheMetaExt), now, now)
+	c.touch(key, int64(len(entry.Audio)))
+	return &entry, nil
+}
+
+// Set stores entry and evicts the least recently used entries beyond MaxBytes.
+func (c *FileCache) Set(_ context.Context, key string, entry *CacheEntry) error {
+	if err := validCacheKey(key); err != nil {
+		return err
+	}
+	if c.opts.MaxBytes > 0 && int64(len(entry.Audio)) > c.opts.MaxBytes {
+		return nil
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The metadata file is written last: an entry without it does not exist.
+	if err = c.writeFile(c.path(key, fileCacheAudioExt), entry.Audio); err != nil {
+		return err
+	}
+	if err = c.writeFile(c.path(key, fileCacheMetaExt), meta); err != nil {
+		return err
+	}
+	c.touch(key, int64(len(entry.Audio)))
+	for c.opts.MaxBytes > 0 && c.size > c.opts.MaxBytes {
+		if err = c.remove(c.order.Back().Value.(*fileCacheItem).key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes the entry for key.
+func (c *FileCache) Delete(_ context.Context, key string) error {
+	if err := validCacheKey(key); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remove(key)
+}
+
+// touch marks key as the most recently used entry with the given audio size.
+func (c *FileCache) touch(key string, size int64) {
+	if elem, ok := c.items[key]; ok {
+		item := elem.Value.(*fileCacheItem)
+		c.size += size - item.size
+		item.size = size
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&fileCacheItem{key: key, size: size})
+	c.size += size
+}
+
+func (c *FileCache) forget(key string) {
+	if elem, ok := c.items[key]; ok {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		c.size -= elem.Value.(*fileCacheItem).size
+	}
+}
+
+func (c *FileCache) remove(key string) error {
+	for _, ext := range []string{fileCacheMetaExt, fileCacheAudioExt} {
+		if err := os.Remove(c.path(key, ext)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	c.forget(key)
+	return nil
+}
+
+func (c *FileCache) writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (c *FileCache) path(key, ext string) string {
+	return filepath.Join(c.dir, key+ext)
+}
+
+// validCacheKey keeps keys from escaping the cache directory.
+func validCacheKey(key string) error {
+	if key == "" || strings.ContainsAny(key, `/\.`) {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package tts
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCacheOptions configures a MemoryCache. Zero values mean no limit.
+type MemoryCacheOptions struct {
+	// MaxBytes bounds the total audio size.
+	MaxBytes int64
+	// MaxEntries bounds the number of entries.
+	MaxEntries int
+	// TTL is how long an entry stays valid after it was stored.
+	TTL time.Duration
+}
+
+// MemoryCache is an in-memory LRU Cache.
+type MemoryCache struct {
+	opts MemoryCacheOptions
+	now  func() time.Time
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+	size  int64
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache creates a MemoryCache.
+func NewMemoryCache(opts MemoryCacheOptions) *MemoryCache {
+	return &MemoryCache{
+		opts:  opts,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for key and marks it as recently used.
+func (c *MemoryCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if c.opts.TTL > 0 && c.now().Sub(item.entry.CreatedAt) > c.opts.TTL {
+		c.remove(elem)
+		return nil, nil
+	}
+	c.order.MoveToFront(elem)
+	return item.entry, nil
+}
+
+// Set stores entry and evicts the least recently used entries beyond the limits.
+// An entry larger than MaxBytes is not stored.
+func (c *MemoryCache) Set(_ context.Context, key string, entry *CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	if c.opts.MaxBytes > 0 && int64(len(entry.Audio)) > c.opts.MaxBytes {
+		return nil
+	}
+	c.items[key] = c.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	c.size += int64(len(entry.Audio))
+	for c.overLimit() {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete removes the entry for key.
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	return nil
+}
+
+// Len returns the number of entries.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *MemoryCache) overLimit() bool {
+	return (c.opts.MaxBytes > 0 && c.size > c.opts.MaxBytes) ||
+		(c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries)
+}
+
+func (c *MemoryCache) remove(elem *list.Element) {
+	item := c.order.Remove(elem).(*memoryCacheItem)
+	delete(c.items, item.key)
+	c.size -= int64(len(item.entry.Audio))
+}
@@ -0,0 +1,222 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheKeyIsStable(t *testing.T) {
+	t.Parallel()
+
+	seed := 7
+	req := SynthesisRequest{
+		VoiceID:       "voice_123",
+		ModelID:       ModelElevenTurboV25,
+		Text:          "Press one for sales.",
+		OutputFormat:  AudioFormatULAW8000,
+		VoiceSettings: &VoiceSettings{Stability: 0.5},
+		Seed:          &seed,
+	}
+	key := CacheKey(req)
+
+	stitched := req
+	stitched.PreviousText = "Welcome."
+	if CacheKey(stitched) != key {
+		t.Error("PreviousText changed the cache key")
+	}
+	otherSeed := 8
+	for name, changed := range map[string]SynthesisRequest{
+		"text":     {VoiceID: req.VoiceID, ModelID: req.ModelID, Text: "Press two.", OutputFormat: req.OutputFormat, VoiceSettings: req.VoiceSettings, Seed: &seed},
+		"format":   {VoiceID: req.VoiceID, ModelID: req.ModelID, Text: req.Text, OutputFormat: AudioFormatPCM8000, VoiceSettings: req.VoiceSettings, Seed: &seed},
+		"settings": {VoiceID: req.VoiceID, ModelID: req.ModelID, Text: req.Text, OutputFormat: req.OutputFormat, VoiceSettings: &VoiceSettings{Stability: 0.6}, Seed: &seed},
+		"seed":     {VoiceID: req.VoiceID, ModelID: req.ModelID, Text: req.Text, OutputFormat: req.OutputFormat, VoiceSettings: req.VoiceSettings, Seed: &otherSeed},
+	} {
+		if CacheKey(changed) == key {
+			t.Errorf("changing %s kept the cache key", name)
+		}
+	}
+}
+
+func TestMemoryCacheEvictsAndExpires(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	cache := NewMemoryCache(MemoryCacheOptions{MaxBytes: 10, TTL: time.Minute})
+	cache.now = func() time.Time { return now }
+
+	_ = cache.Set(ctx, "a", &CacheEntry{Audio: []byte("aaaa"), CreatedAt: now})
+	_ = cache.Set(ctx, "b", &CacheEntry{Audio: []byte("bbbb"), CreatedAt: now})
+	if entry, _ := cache.Get(ctx, "a"); entry == nil {
+		t.Fatal("Get(a) missed")
+	}
+	_ = cache.Set(ctx, "c", &CacheEntry{Audio: []byte("cccc"), CreatedAt: now})
+	if entry, _ := cache.Get(ctx, "b"); entry != nil {
+		t.Error("least recently used entry b was not evicted")
+	}
+	if got, want := cache.Len(), 2; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if entry, _ := cache.Get(ctx, "a"); entry != nil {
+		t.Error("expired entry a was returned")
+	}
+}
+
+func TestFileCacheRoundTripAndEviction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache, err := NewFileCache(t.TempDir(), FileCacheOptions{MaxBytes: 10, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err = cache.Set(ctx, "old", &CacheEntry{Audio: []byte("123456"), ContentType: "audio/mpeg", CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := cache.Get(ctx, "old")
+	if err != nil || entry == nil {
+		t.Fatalf("Get() = %v, %v", entry, err)
+	}
+	if got, want := string(entry.Audio), "123456"; got != want {
+		t.Errorf("Audio = %q, want %q", got, want)
+	}
+	if got, want := entry.ContentType, "audio/mpeg"; got != want {
+		t.Errorf("ContentType = %q, want %q", got, want)
+	}
+
+	// Age the first entry so that it is the least recently used.
+	past := now.Add(-time.Hour)
+	_ = os.Chtimes(cache.path("old", fileCacheMetaExt), past, past)
+	if err = cache.Set(ctx, "new", &CacheEntry{Audio: []byte("abcdef"), CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ = cache.Get(ctx, "old"); entry != nil {
+		t.Error("old entry was not evicted")
+	}
+	if entry, _ = cache.Get(ctx, "new"); entry == nil {
+		t.Error("new entry missing")
+	}
+	if err = cache.Set(ctx, "../escape", &CacheEntry{}); err == nil {
+		t.Error("Set() accepted a key with a path")
+	}
+}
+
+func TestFileCacheEvictsEntriesLoadedAtOpen(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	first, err := NewFileCache(dir, FileCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, key := range []string{"a", "b"} {
+		if err = first.Set(ctx, key, &CacheEntry{Audio: []byte("1234"), CreatedAt: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := now.Add(-time.Hour)
+	_ = os.Chtimes(first.path("a", fileCacheMetaExt), past, past)
+
+	cache, err := NewFileCache(dir, FileCacheOptions{MaxBytes: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cache.size, int64(8); got != want {
+		t.Errorf("size = %d, want %d", got, want)
+	}
+	if err = cache.Set(ctx, "c", &CacheEntry{Audio: []byte("1234"), CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := cache.Get(ctx, "a"); entry != nil {
+		t.Error("least recently used entry was not evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if entry, _ := cache.Get(ctx, key); entry == nil {
+			t.Errorf("entry %q missing", key)
+		}
+	}
+	if got, want := cache.size, int64(8); got != want {
+		t.Errorf("size = %d, want %d", got, want)
+	}
+}
+
+func TestCachedClientServesRepeatedRequestsFromCache(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("request-id", "req_1")
+		_, _ = w.Write([]byte("prompt audio"))
+	}))
+	defer server.Close()
+
+	client := NewCachedClient(NewClientWithConfig(ClientConfig{authKey: "test-key", BaseURL: server.URL}),
+		NewMemoryCache(MemoryCacheOptions{}), CachedClientOptions{})
+	req := SynthesisRequest{VoiceID: "voice_123", Text: "Press one."}
+	ctx := context.Background()
+
+	stream, err := client.StreamAudio(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.ReadAll(stream.Audio); err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.Audio.Close()
+
+	resp, err := client.Synthesize(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Cached || string(resp.Audio) != "prompt audio" || resp.RequestID != "req_1" {
+		t.Errorf("Synthesize() = %+v, want cached prompt audio", resp)
+	}
+	stream, err = client.StreamAudio(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(stream.Audio); !stream.Cached || string(body) != "prompt audio" {
+		t.Errorf("StreamAudio() body = %q, cached = %v", body, stream.Cached)
+	}
+	if got, want := calls.Load(), int64(1); got != want {
+		t.Errorf("API calls = %d, want %d", got, want)
+	}
+}
+
+func TestCachedClientCopiesAudio(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("prompt audio"))
+	}))
+	defer server.Close()
+
+	client := NewCachedClient(NewClientWithConfig(ClientConfig{authKey: "test-key", BaseURL: server.URL}),
+		NewMemoryCache(MemoryCacheOptions{}), CachedClientOptions{})
+	req := SynthesisRequest{VoiceID: "voice_123", Text: "Press one."}
+	ctx := context.Background()
+
+	for range 2 {
+		resp, err := client.Synthesize(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(resp.Audio), "prompt audio"; got != want {
+			t.Fatalf("audio = %q, want %q", got, want)
+		}
+		copy(resp.Audio, "XXXXXX")
+	}
+}
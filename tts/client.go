@@ -8,21 +8,26 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
 )
 
 type Client struct {
 	config ClientConfig
+	// http is config.HTTPClient chained with config.HTTPMiddleware once, so that stateful
+	// middlewares are shared by all calls.
+	http *http.Client
 }
 
 func NewClient(authKey string) *Client {
-	return &Client{
-		config: DefaultConfig(authKey),
-	}
+	return NewClientWithConfig(DefaultConfig(authKey))
 }
 
 func NewClientWithConfig(config ClientConfig) *Client {
 	return &Client{
 		config: config,
+		http:   transcripts.ChainHTTPClient(config.HTTPClient, config.HTTPMiddleware...),
 	}
 }
 
@@ -32,7 +37,8 @@ func (c *Client) Synthesize(ctx context.Context, req SynthesisRequest) (*Synthes
 		return nil, err
 	}
 
-	resp, err := c.httpClient().Do(httpReq)
+	start := time.Now()
+	resp, err := c.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +48,8 @@ func (c *Client) Synthesize(ctx context.Context, req SynthesisRequest) (*Synthes
 		return nil, parseAPIError(resp)
 	}
 
-	audio, err := io.ReadAll(resp.Body)
+	latency, body := c.timeResponse(ctx, start, resp.Body, resp.Header.Get("request-id"))
+	audio, err := io.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +60,7 @@ func (c *Client) Synthesize(ctx context.Context, req SynthesisRequest) (*Synthes
 		RequestID:      resp.Header.Get("request-id"),
 		CharacterCount: characterCount(resp.Header),
 		Headers:        resp.Header.Clone(),
+		Latency:        latency,
 	}, nil
 }
 
@@ -64,7 +72,8 @@ func (c *Client) StreamAudio(ctx context.Context, req SynthesisRequest) (*Stream
 		return nil, err
 	}
 
-	resp, err := c.httpClient().Do(httpReq)
+	start := time.Now()
+	resp, err := c.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +82,10 @@ func (c *Client) StreamAudio(ctx context.Context, req SynthesisRequest) (*Stream
 		return nil, parseAPIError(resp)
 	}
 
+	latency, body := c.timeResponse(ctx, start, resp.Body, resp.Header.Get("request-id"))
 	return &StreamResponse{
-		Audio:          resp.Body,
+		Audio:          body,
+		Latency:        latency,
 		ContentType:    resp.Header.Get("Content-Type"),
 		RequestID:      resp.Header.Get("request-id"),
 		CharacterCount: characterCount(resp.Header),
@@ -109,10 +120,7 @@ func (c *Client) newRequest(ctx context.Context, method, url string, req Synthes
 }
 
 func (c *Client) httpClient() *http.Client {
-	if c.config.HTTPClient != nil {
-		return c.config.HTTPClient
-	}
-	return http.DefaultClient
+	return c.http
 }
 
 func (c *Client) synthesizeURL(voiceID string) string {
@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
 )
 
 func TestClientSynthesizeReturnsAudioAndMetadata(t *testing.T) {
@@ -269,3 +271,52 @@ func TestClientSynthesizeReturnsAPIError(t *testing.T) {
 		t.Fatalf("Message = %s, want %s", got, want)
 	}
 }
+
+func TestClientHTTPMiddlewareWrapsRequests(t *testing.T) {
+	t.Parallel()
+
+	var tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	var (
+		audited []string
+		wrapped int
+	)
+	cfg := DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	cfg.HTTPMiddleware = []transcripts.HTTPMiddleware{
+		func(next http.RoundTripper) http.RoundTripper {
+			wrapped++
+			return transcripts.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				audited = append(audited, req.Method+" "+req.URL.Path)
+				return next.RoundTrip(req)
+			})
+		},
+		func(next http.RoundTripper) http.RoundTripper {
+			return transcripts.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.Header.Set("X-Tenant", "acme")
+				return next.RoundTrip(req)
+			})
+		},
+	}
+	client := NewClientWithConfig(cfg)
+	for range 2 {
+		if _, err := client.Synthesize(context.Background(), SynthesisRequest{VoiceID: "voice_123", Text: "Hi."}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := tenant, "acme"; got != want {
+		t.Errorf("X-Tenant = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(audited, ","), "POST /v1/text-to-speech/voice_123,POST /v1/text-to-speech/voice_123"; got != want {
+		t.Errorf("audited = %q, want %q", got, want)
+	}
+	if got, want := wrapped, 1; got != want {
+		t.Errorf("middleware built %d times, want %d", got, want)
+	}
+}
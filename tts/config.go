@@ -1,6 +1,10 @@
 package tts
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
 
 const (
 	BaseURL = "https://api.elevenlabs.io"
@@ -10,6 +14,18 @@ type ClientConfig struct {
 	authKey    string
 	BaseURL    string
 	HTTPClient *http.Client
+	// LatencyObserver, if set, receives the time to first byte of HTTP requests and the latencies
+	// of realtime sessions. Responses carry the same values.
+	LatencyObserver transcripts.LatencyObserver
+	// Logger receives debug logs of HTTP requests and is the default logger of realtime connections.
+	// Use a transcripts.StructuredLogger such as transcripts.SlogLogger to get the fields as attributes.
+	Logger transcripts.Logger
+	// HTTPMiddleware wraps the transport of every HTTP call, the first entry outermost. Use it to add
+	// headers, audit requests or rewrite URLs without replacing HTTPClient.
+	HTTPMiddleware []transcripts.HTTPMiddleware
+	// WebSocketMiddleware intercepts the dial, reads and writes of every realtime connection,
+	// the first entry outermost.
+	WebSocketMiddleware []transcripts.WebSocketMiddleware
 }
 
 func DefaultConfig(authKey string) ClientConfig {
@@ -0,0 +1,145 @@
+package tts
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// InterruptResult describes the audio delivered to handlers before an interruption.
+type InterruptResult struct {
+	// AudioBytes is the amount of audio delivered since the previous interruption.
+	AudioBytes int64
+	// Chunks is the number of AudioEvents delivered since the previous interruption.
+	Chunks int
+	// Text is the text covered by the delivered audio, joined from the alignment sent with it.
+	// It is empty when the server sends no alignment.
+	Text string
+	// DroppedChunks is the number of AudioEvents dropped during the previous interruption.
+	DroppedChunks int
+}
+
+// Interrupt stops the current generation, for example when the user starts talking over the agent,
+// and drops the audio of the interrupted text. The connection stays open and nothing is flushed, so
+// the server does not generate the interrupted text on purpose.
+//
+// On a multi-context connection it closes the current context, which stops its generation and
+// discards its buffered text, and sends later text to a new context. Every event of the closed
+// context that still arrives is dropped.
+//
+// The stream-input endpoint has a single buffer that cannot be cleared. Interrupt drops audio by the
+// alignment sent with it: every AudioEvent that only covers text sent before the interruption is
+// dropped, including text the server still had buffered and generates together with the next text.
+// An AudioEvent that covers both old and new text is delivered. AudioEvents without alignment are
+// dropped until the next Send, SendText or SendTokens call. Use ConnectMultiContext for barge-in
+// when the interrupted text must not be generated at all.
+func (s *Streamer) Interrupt() (InterruptResult, error) {
+	if s.conn.multiContext {
+		s.writeMu.Lock()
+		defer s.writeMu.Unlock()
+	}
+	s.mu.Lock()
+	result := InterruptResult{
+		AudioBytes:    s.delivered.bytes,
+		Chunks:        s.delivered.chunks,
+		Text:          s.delivered.text.String(),
+		DroppedChunks: s.dropped,
+	}
+	s.interrupted = true
+	s.cutoff = s.sentChars
+	s.firstText, s.audioSeen = time.Time{}, false
+	s.delivered = deliveryStats{}
+	s.dropped = 0
+	closed, open := s.context, s.contextOpen
+	if s.conn.multiContext && open {
+		if s.droppedContexts == nil {
+			s.droppedContexts = make(map[string]bool)
+		}
+		s.droppedContexts[closed] = true
+		s.nextContextLocked()
+	}
+	s.mu.Unlock()
+	if s.conn.multiContext && open {
+		return result, s.conn.CloseContext(s.ctx, closed)
+	}
+	return result, nil
+}
+
+// Interrupted reports whether Interrupt was called and neither a Send nor audio of later text
+// followed yet.
+func (s *Streamer) Interrupted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interrupted
+}
+
+// deliver reports whether event should reach the handlers and records delivered audio.
+func (s *Streamer) deliver(event StreamEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch e := event.(type) {
+	case DoneEvent:
+		return !s.droppedContexts[e.ContextID]
+	case ErrorEvent:
+		return !s.droppedContexts[e.ContextID]
+	case AudioEvent:
+		if s.staleLocked(e) {
+			s.dropped++
+			return false
+		}
+		s.delivered.bytes += int64(len(e.Audio))
+		s.delivered.chunks++
+		if e.Alignment != nil {
+			for _, char := range e.Alignment.Chars {
+				s.delivered.text.WriteString(char)
+			}
+		}
+	}
+	return true
+}
+
+// staleLocked reports whether audio belongs to interrupted text.
+func (s *Streamer) staleLocked(audio AudioEvent) bool {
+	if s.conn.multiContext {
+		return s.droppedContexts[audio.ContextID]
+	}
+	if audio.Alignment != nil {
+		if n := countChars(audio.Alignment.Chars...); n > 0 {
+			s.alignChars += n
+			if s.alignChars <= s.cutoff {
+				return true
+			}
+			s.interrupted = false
+			return false
+		}
+	}
+	return s.interrupted
+}
+
+// resume records text sent with Send and ends the dropping of audio without alignment.
+func (s *Streamer) resume(text string) {
+	s.mu.Lock()
+	s.interrupted = false
+	s.sentChars += countChars(text)
+	s.mu.Unlock()
+}
+
+// countChars counts the non-space characters of text, which the text sent and the alignment
+// received have in common.
+func countChars(text ...string) int {
+	n := 0
+	for _, part := range text {
+		for _, r := range part {
+			if !unicode.IsSpace(r) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+type deliveryStats struct {
+	bytes  int64
+	chunks int
+	text   strings.Builder
+}
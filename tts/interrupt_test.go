@@ -0,0 +1,264 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func TestStreamerInterruptDropsAudioOfInterruptedText(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		ctx := r.Context()
+		read := func() StreamTextMessage {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				t.Errorf("read message: %v", err)
+			}
+			var msg StreamTextMessage
+			_ = json.Unmarshal(data, &msg)
+			return msg
+		}
+		write := func(msg string) {
+			_ = conn.Write(ctx, websocket.MessageText, []byte(msg))
+		}
+		read() // init
+		read() // Hi there.
+		write(`{"audio":"YWJj","alignment":{"chars":["H","i"," "],"charStartTimesMs":[0,50,100],"charDurationsMs":[50,50,50]}}`)
+		if msg := read(); msg.Text != "Next " || msg.Flush != nil {
+			t.Errorf("message after Interrupt = %+v, want the next text without a flush", msg)
+		}
+		write(`{"audio":"eHl6","alignment":{"chars":["t","h","e","r","e","."," "],"charStartTimesMs":[0,0,0,0,0,0,0],"charDurationsMs":[0,0,0,0,0,0,0]}}`)
+		write(`{"audio":"ZGVm","alignment":{"chars":["N","e","x","t"," "],"charStartTimesMs":[0,0,0,0,0],"charDurationsMs":[0,0,0,0,0]}}`)
+		write(`{"isFinal":true}`)
+	}))
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	first := make(chan struct{})
+	streamer := connectTestStreamer(t, server)
+	streamer.handlers = append(streamer.handlers, func(_ context.Context, event StreamEvent) {
+		if audio, ok := event.(AudioEvent); ok {
+			mu.Lock()
+			received = append(received, string(audio.Audio))
+			if len(received) == 1 {
+				close(first)
+			}
+			mu.Unlock()
+		}
+	})
+	streamer.Start()
+	if err := streamer.SendText("Hi there. "); err != nil {
+		t.Fatal(err)
+	}
+
+	<-first
+	result, err := streamer.Interrupt()
+	if err != nil {
+		t.Fatalf("Interrupt() error = %v", err)
+	}
+	if result.AudioBytes != 3 || result.Chunks != 1 || result.Text != "Hi " {
+		t.Errorf("Interrupt() = %+v, want 3 bytes, 1 chunk, text %q", result, "Hi ")
+	}
+	if !streamer.Interrupted() {
+		t.Error("Interrupted() = false before the next Send")
+	}
+	if err = streamer.SendText("Next "); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-streamer.Err(); err != nil {
+		t.Fatalf("streamer error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := strings.Join(received, ","), "abc,def"; got != want {
+		t.Errorf("audio = %q, want %q", got, want)
+	}
+	if got, want := streamer.dropped, 1; got != want {
+		t.Errorf("dropped = %d, want %d", got, want)
+	}
+}
+
+func TestStreamerDeliverWithoutAlignment(t *testing.T) {
+	t.Parallel()
+
+	aligned := func(text string) AudioEvent {
+		var alignment Alignment
+		for _, r := range text {
+			alignment.Chars = append(alignment.Chars, string(r))
+		}
+		return AudioEvent{Audio: []byte("a"), Alignment: &alignment}
+	}
+	streamer := NewRealtimeSynthesizer(context.Background(), &Conn{})
+	streamer.resume("One two.")
+	if _, err := streamer.Interrupt(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, step := range []struct {
+		event AudioEvent
+		want  bool
+	}{
+		{AudioEvent{Audio: []byte("a")}, false},
+		{aligned("One "), false},
+		{aligned("two. Three"), true},
+		{AudioEvent{Audio: []byte("a")}, true},
+	} {
+		if i == 2 {
+			streamer.resume(" Three")
+		}
+		if got := streamer.deliver(step.event); got != step.want {
+			t.Errorf("deliver(event %d) = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestStreamerInterruptClosesTheContext(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/multi-stream-input") {
+			t.Errorf("path = %q, want the multi-stream-input endpoint", r.URL.Path)
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		ctx := r.Context()
+		expect := func(want string) {
+			t.Helper()
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				t.Errorf("read message: %v", err)
+			}
+			if string(data) != want {
+				t.Errorf("message = %s, want %s", data, want)
+			}
+		}
+		write := func(msg string) {
+			_ = conn.Write(ctx, websocket.MessageText, []byte(msg))
+		}
+		expect(`{"text":" ","context_id":"ctx_1"}`)
+		expect(`{"text":"Hello ","context_id":"ctx_1"}`)
+		write(`{"audio":"YWJj","contextId":"ctx_1"}`)
+		expect(`{"context_id":"ctx_1","close_context":true}`)
+		write(`{"audio":"eHl6","contextId":"ctx_1"}`)
+		write(`{"isFinal":true,"contextId":"ctx_1"}`)
+		expect(`{"text":" ","context_id":"ctx_2"}`)
+		expect(`{"text":"Next ","context_id":"ctx_2"}`)
+		write(`{"audio":"ZGVm","contextId":"ctx_2"}`)
+		expect(`{"context_id":"ctx_2","flush":true}`)
+		expect(`{"close_socket":true}`)
+		write(`{"isFinal":true,"contextId":"ctx_2"}`)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	conn, err := NewClientWithConfig(cfg).ConnectMultiContext(context.Background(), StreamInputRequest{VoiceID: "voice_123"})
+	if err != nil {
+		t.Fatalf("ConnectMultiContext() error = %v", err)
+	}
+	defer conn.Close()
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	first := make(chan struct{})
+	streamer := NewRealtimeSynthesizer(context.Background(), conn, func(_ context.Context, event StreamEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e := event.(type) {
+		case AudioEvent:
+			events = append(events, string(e.Audio)+"@"+e.ContextID)
+			if len(events) == 1 {
+				close(first)
+			}
+		case DoneEvent:
+			events = append(events, "final@"+e.ContextID)
+		}
+	})
+	streamer.Start()
+	if err = streamer.SendText("Hello "); err != nil {
+		t.Fatal(err)
+	}
+	<-first
+	if _, err = streamer.Interrupt(); err != nil {
+		t.Fatalf("Interrupt() error = %v", err)
+	}
+	if got, want := streamer.ContextID(), "ctx_2"; got != want {
+		t.Errorf("ContextID() = %q, want %q", got, want)
+	}
+	if err = streamer.SendText("Next "); err != nil {
+		t.Fatal(err)
+	}
+	if err = streamer.CloseInput(); err != nil {
+		t.Fatal(err)
+	}
+	if err = streamer.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := strings.Join(events, ","), "abc@ctx_1,def@ctx_2,final@ctx_2"; got != want {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestConnectMultiContextSendsModelAndLanguage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Path, "/v1/text-to-speech/voice_123/multi-stream-input"; got != want {
+			t.Errorf("path = %s, want %s", got, want)
+		}
+		if got, want := r.URL.Query().Get("model_id"), ModelElevenFlashV25; got != want {
+			t.Errorf("model_id = %q, want %q", got, want)
+		}
+		if got, want := r.URL.Query().Get("language_code"), "de"; got != want {
+			t.Errorf("language_code = %q, want %q", got, want)
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		conn.Close(websocket.StatusPolicyViolation, "invalid model")
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	_, err := NewClientWithConfig(cfg).ConnectMultiContext(context.Background(), StreamInputRequest{
+		VoiceID:      "voice_123",
+		ModelID:      ModelElevenFlashV25,
+		LanguageCode: "de",
+	}, WithReadyTimeout(5*time.Second))
+	if got := websocket.CloseStatus(err); got != websocket.StatusPolicyViolation {
+		t.Errorf("ConnectMultiContext() error = %v, want close status %v", err, websocket.StatusPolicyViolation)
+	}
+}
@@ -0,0 +1,132 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
+
+// WithLatencyObserver reports the dial time of the connection, and the time to first audio of a
+// Streamer running on it, to observer. It defaults to ClientConfig.LatencyObserver.
+func WithLatencyObserver(observer transcripts.LatencyObserver) ConnectOption {
+	return func(opts *connectOption) {
+		opts.latency = observer
+	}
+}
+
+// RequestLatency holds the latencies of one HTTP request.
+type RequestLatency struct {
+	// TimeToHeaders is the time from sending the request to receiving the response headers.
+	TimeToHeaders time.Duration
+
+	start     time.Time
+	firstByte atomic.Int64
+}
+
+// TimeToFirstByte returns the time from sending the request to reading the first audio byte,
+// or zero before the first byte was read. For StreamAudio it is set by the first read of Audio.
+func (l *RequestLatency) TimeToFirstByte() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return time.Duration(l.firstByte.Load())
+}
+
+// firstByteReader records RequestLatency.TimeToFirstByte on the first read that returns data.
+type firstByteReader struct {
+	io.ReadCloser
+	latency *RequestLatency
+	observe func(time.Duration)
+}
+
+func (r *firstByteReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && r.observe != nil {
+		d := time.Since(r.latency.start)
+		r.latency.firstByte.Store(int64(d))
+		r.observe(d)
+		r.observe = nil
+	}
+	return n, err
+}
+
+// timeResponse measures the latency of a request sent at start and wraps the response body
+// to catch the first byte.
+func (c *Client) timeResponse(ctx context.Context, start time.Time, body io.ReadCloser, requestID string) (*RequestLatency, io.ReadCloser) {
+	latency := &RequestLatency{TimeToHeaders: time.Since(start), start: start}
+	return latency, &firstByteReader{
+		ReadCloser: body,
+		latency:    latency,
+		observe: func(d time.Duration) {
+			if c.config.LatencyObserver != nil {
+				c.config.LatencyObserver.ObserveLatency(ctx, transcripts.LatencyMeasurement{
+					Metric:    transcripts.LatencyTimeToFirstByte,
+					Duration:  d,
+					RequestID: requestID,
+				})
+			}
+		},
+	}
+}
+
+// DialDuration returns the time it took to open the websocket.
+func (c *Conn) DialDuration() time.Duration {
+	return c.dialDuration
+}
+
+func (c *Conn) observeLatency(ctx context.Context, m transcripts.LatencyMeasurement) {
+	if c.latency == nil {
+		return
+	}
+	if resp := c.conn.Response(); resp != nil {
+		m.RequestID = resp.Header.Get("request-id")
+	}
+	c.latency.ObserveLatency(ctx, m)
+}
+
+// StreamerLatency holds the latencies measured on a realtime session. Zero means not measured yet.
+type StreamerLatency struct {
+	Dial time.Duration
+	// TimeToFirstAudio is measured from the first text sent to the first audio received.
+	// It is measured again for the first text sent after each Interrupt.
+	TimeToFirstAudio time.Duration
+}
+
+// Latency returns the latencies measured so far.
+func (s *Streamer) Latency() StreamerLatency {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StreamerLatency{Dial: s.conn.dialDuration, TimeToFirstAudio: s.timeToFirstAudio}
+}
+
+func (s *Streamer) markText(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.firstText.IsZero() {
+		s.firstText = time.Now()
+	}
+}
+
+// observeAudio runs on the read goroutine for every delivered event.
+func (s *Streamer) observeAudio(event StreamEvent) {
+	if _, ok := event.(AudioEvent); !ok {
+		return
+	}
+	s.mu.Lock()
+	if s.firstText.IsZero() || s.audioSeen {
+		s.mu.Unlock()
+		return
+	}
+	s.audioSeen = true
+	s.timeToFirstAudio = time.Since(s.firstText)
+	d := s.timeToFirstAudio
+	s.mu.Unlock()
+	s.conn.observeLatency(s.ctx, transcripts.LatencyMeasurement{Metric: transcripts.LatencyTimeToFirstAudio, Duration: d})
+}
@@ -0,0 +1,118 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
+
+type latencyRecorder struct {
+	mu           sync.Mutex
+	measurements []transcripts.LatencyMeasurement
+}
+
+func (r *latencyRecorder) ObserveLatency(_ context.Context, m transcripts.LatencyMeasurement) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.measurements = append(r.measurements, m)
+}
+
+func (r *latencyRecorder) metrics() []transcripts.LatencyMetric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var metrics []transcripts.LatencyMetric
+	for _, m := range r.measurements {
+		metrics = append(metrics, m.Metric)
+	}
+	return metrics
+}
+
+func TestStreamAudioMeasuresTimeToFirstByte(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "req_1")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	recorder := &latencyRecorder{}
+	client := NewClientWithConfig(ClientConfig{authKey: "test-key", BaseURL: server.URL, LatencyObserver: recorder})
+	stream, err := client.StreamAudio(context.Background(), SynthesisRequest{VoiceID: "voice_123", Text: "Hi."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Audio.Close()
+	if got := stream.Latency.TimeToFirstByte(); got != 0 {
+		t.Errorf("TimeToFirstByte() before reading = %v, want 0", got)
+	}
+	if _, err = io.ReadAll(stream.Audio); err != nil {
+		t.Fatal(err)
+	}
+	if stream.Latency.TimeToHeaders <= 0 || stream.Latency.TimeToFirstByte() < stream.Latency.TimeToHeaders {
+		t.Errorf("Latency = headers %v, first byte %v", stream.Latency.TimeToHeaders, stream.Latency.TimeToFirstByte())
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.measurements) != 1 || recorder.measurements[0].Metric != transcripts.LatencyTimeToFirstByte ||
+		recorder.measurements[0].RequestID != "req_1" {
+		t.Errorf("measurements = %+v, want one time_to_first_byte for req_1", recorder.measurements)
+	}
+}
+
+func TestStreamerMeasuresDialAndTimeToFirstAudio(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		ctx := r.Context()
+		for i := 0; i < 2; i++ {
+			if _, _, err = conn.Read(ctx); err != nil {
+				return
+			}
+		}
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"audio":"YWJj"}`))
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"isFinal":true}`))
+	}))
+	defer server.Close()
+
+	recorder := &latencyRecorder{}
+	cfg := DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	conn, err := NewClientWithConfig(cfg).ConnectRealtime(context.Background(), StreamInputRequest{VoiceID: "voice_123"},
+		WithLatencyObserver(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	streamer := NewRealtimeSynthesizer(context.Background(), conn)
+	streamer.Start()
+	if err = streamer.SendText("Hello. "); err != nil {
+		t.Fatal(err)
+	}
+	if err = streamer.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	latency := streamer.Latency()
+	if latency.Dial <= 0 || latency.TimeToFirstAudio <= 0 {
+		t.Errorf("Latency() = %+v, want dial and time to first audio", latency)
+	}
+	got := recorder.metrics()
+	if len(got) != 2 || got[0] != transcripts.LatencyDial || got[1] != transcripts.LatencyTimeToFirstAudio {
+		t.Errorf("observed = %v, want [dial time_to_first_audio]", got)
+	}
+}
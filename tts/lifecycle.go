@@ -0,0 +1,160 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
+
+// ErrInputClosed is returned when text is sent after CloseInput.
+var ErrInputClosed = errors.New("tts: input closed")
+
+// minKeepAliveTick bounds how often the keep-alive loop checks for idle time.
+const minKeepAliveTick = 10 * time.Millisecond
+
+// WithReadyTimeout makes ConnectRealtime wait up to timeout for the server to reject the
+// initialization message before it treats the session as ready. A healthy session sends nothing
+// until text arrives, so every successful connect takes the full timeout. The check is off by
+// default; a rejection then shows up on the first ReadEvent.
+func WithReadyTimeout(timeout time.Duration) ConnectOption {
+	return func(opts *connectOption) {
+		opts.readyTimeout = timeout
+	}
+}
+
+// StreamerState is the lifecycle state of a Streamer.
+type StreamerState int32
+
+const (
+	// StreamerStateIdle means Start has not been called.
+	StreamerStateIdle StreamerState = iota
+	// StreamerStateStreaming means text can be sent and audio is being received.
+	StreamerStateStreaming
+	// StreamerStateInputClosed means the end-of-stream frame was sent and the remaining audio is being received.
+	StreamerStateInputClosed
+	// StreamerStateDone means the final event arrived or the session ended.
+	StreamerStateDone
+	// StreamerStateClosed means the connection was closed.
+	StreamerStateClosed
+)
+
+func (s StreamerState) String() string {
+	switch s {
+	case StreamerStateIdle:
+		return "idle"
+	case StreamerStateStreaming:
+		return "streaming"
+	case StreamerStateInputClosed:
+		return "input_closed"
+	case StreamerStateDone:
+		return "done"
+	case StreamerStateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// State returns the lifecycle state.
+func (s *Streamer) State() StreamerState {
+	return StreamerState(s.state.Load())
+}
+
+// SetKeepAlive sends a single space, which the server ignores, when no text was sent for interval,
+// so that long pauses do not hit the session's inactivity_timeout. Keep interval below that timeout,
+// which defaults to 20 seconds. Keep-alives stop after CloseInput. An interval of zero or less
+// disables them. It must be called before Start.
+func (s *Streamer) SetKeepAlive(interval time.Duration) {
+	s.keepAlive = max(interval, 0)
+}
+
+// Wait blocks until the final event arrives or the session ends, and returns the session error.
+// Unlike Err, it can be called any number of times.
+func (s *Streamer) Wait(ctx context.Context) error {
+	select {
+	case <-s.done:
+		return s.runErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown ends the session cleanly: it sends the end-of-stream frame, waits for the remaining audio
+// and the final event, and closes the connection with a normal closure status.
+func (s *Streamer) Shutdown(ctx context.Context) error {
+	var err error
+	if s.State() < StreamerStateInputClosed {
+		err = s.CloseInput()
+	}
+	if err == nil {
+		err = s.Wait(ctx)
+	}
+	return errors.Join(err, s.Close())
+}
+
+func (s *Streamer) keepAliveLoop() {
+	ticker := time.NewTicker(max(s.keepAlive/4, minKeepAliveTick))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if s.State() != StreamerStateStreaming {
+				return
+			}
+			if now.Sub(time.Unix(0, s.lastSend.Load())) < s.keepAlive {
+				continue
+			}
+			if err := s.write(StreamTextMessage{Text: " "}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+type readResult struct {
+	messageType transcripts.MessageType
+	data        []byte
+	err         error
+}
+
+// awaitReady reads the first message for up to timeout. An error event or a closed connection is
+// returned as the error. Any other message, and the read still running when the timeout passes, is
+// kept for the next ReadEvent.
+func (c *Conn) awaitReady(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	pending := make(chan readResult, 1)
+	go func() {
+		// A canceled read closes the connection, so it must outlive ctx and the timeout.
+		messageType, data, err := c.conn.ReadMessage(context.WithoutCancel(ctx))
+		pending <- readResult{messageType: messageType, data: data, err: err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case result := <-pending:
+		if result.err != nil {
+			return result.err
+		}
+		if result.messageType == transcripts.MessageText {
+			if event, err := unmarshalStreamEvent(result.data); err == nil {
+				if e, ok := event.(ErrorEvent); ok {
+					return e
+				}
+			}
+		}
+		pending <- result
+	case <-timer.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	c.pending = pending
+	return nil
+}
@@ -0,0 +1,186 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func TestStreamerLifecycleWithKeepAliveAndShutdown(t *testing.T) {
+	t.Parallel()
+
+	keptAlive := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		ctx := r.Context()
+		var texts []string
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				t.Errorf("read message: %v", err)
+				return
+			}
+			var msg StreamTextMessage
+			_ = json.Unmarshal(data, &msg)
+			texts = append(texts, msg.Text)
+			if len(texts) == 2 {
+				close(keptAlive)
+			}
+			if msg.Text == "" {
+				if msg.Flush != nil {
+					t.Errorf("end-of-stream frame has flush = %v", *msg.Flush)
+				}
+				break
+			}
+		}
+		if got, want := texts[len(texts)-2], "Hello. "; got != want {
+			t.Errorf("last text = %q, want %q", got, want)
+		}
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"audio":"YWJj"}`))
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"isFinal":true}`))
+	}))
+	defer server.Close()
+
+	streamer := connectTestStreamer(t, server)
+	if got, want := streamer.State(), StreamerStateIdle; got != want {
+		t.Errorf("State() = %v, want %v", got, want)
+	}
+	streamer.SetKeepAlive(20 * time.Millisecond)
+	streamer.Start()
+	if got, want := streamer.State(), StreamerStateStreaming; got != want {
+		t.Errorf("State() = %v, want %v", got, want)
+	}
+
+	select {
+	case <-keptAlive:
+	case <-time.After(time.Second):
+		t.Fatal("no keep-alive sent")
+	}
+	if err := streamer.SendText("Hello. "); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := streamer.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got, want := streamer.State(), StreamerStateClosed; got != want {
+		t.Errorf("State() = %v, want %v", got, want)
+	}
+	if err := streamer.SendText("late"); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("SendText() after Shutdown error = %v, want ErrInputClosed", err)
+	}
+	if err := streamer.Wait(ctx); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
+
+func TestStreamerKeepAliveHandlesTinyAndNegativeIntervals(t *testing.T) {
+	t.Parallel()
+
+	keptAlive := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.CloseNow()
+		for messages := 0; ; messages++ {
+			if _, _, err = conn.Read(r.Context()); err != nil {
+				return
+			}
+			if messages > 0 {
+				select {
+				case keptAlive <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	streamer := connectTestStreamer(t, server)
+	streamer.SetKeepAlive(-time.Second)
+	if got := streamer.keepAlive; got != 0 {
+		t.Errorf("keepAlive = %v after a negative interval, want 0", got)
+	}
+	streamer.SetKeepAlive(3 * time.Nanosecond)
+	streamer.Start()
+	defer streamer.Close()
+	select {
+	case <-keptAlive:
+	case <-time.After(time.Second):
+		t.Fatal("no keep-alive sent")
+	}
+}
+
+func TestConnectRealtimeReturnsRejectedInitialization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		reply func(ctx context.Context, conn *websocket.Conn)
+		check func(err error) bool
+	}{
+		{
+			name: "error event",
+			reply: func(ctx context.Context, conn *websocket.Conn) {
+				_ = conn.Write(ctx, websocket.MessageText, []byte(`{"message":"invalid model_id"}`))
+			},
+			check: func(err error) bool {
+				var event ErrorEvent
+				return errors.As(err, &event) && event.Message == "invalid model_id"
+			},
+		},
+		{
+			name: "close status",
+			reply: func(ctx context.Context, conn *websocket.Conn) {
+				_ = conn.Close(websocket.StatusPolicyViolation, "invalid voice_id")
+			},
+			check: func(err error) bool {
+				return websocket.CloseStatus(err) == websocket.StatusPolicyViolation
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := websocket.Accept(w, r, nil)
+				if err != nil {
+					t.Errorf("accept websocket: %v", err)
+					return
+				}
+				defer conn.CloseNow()
+				if _, _, err = conn.Read(r.Context()); err != nil {
+					t.Errorf("read init message: %v", err)
+					return
+				}
+				tt.reply(r.Context(), conn)
+			}))
+			defer server.Close()
+
+			cfg := DefaultConfig("test-key")
+			cfg.BaseURL = server.URL
+			_, err := NewClientWithConfig(cfg).ConnectRealtime(context.Background(), StreamInputRequest{VoiceID: "voice_123"},
+				WithReadyTimeout(5*time.Second))
+			if err == nil || !tt.check(err) {
+				t.Errorf("ConnectRealtime() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package tts
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
+
+func (c *Client) logger() transcripts.Logger {
+	if c.config.Logger != nil {
+		return c.config.Logger
+	}
+	return transcripts.NopLogger{}
+}
+
+// do sends req and logs it at debug level.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.httpClient().Do(req)
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", transcripts.RedactURL(req.URL)),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		transcripts.LogAttrs(req.Context(), c.config.Logger, slog.LevelDebug, "http request failed", append(attrs, slog.Any("error", err))...)
+		return nil, err
+	}
+	attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.String("request_id", resp.Header.Get("request-id")))
+	if count := characterCount(resp.Header); count != "" {
+		attrs = append(attrs, slog.String("character_count", count))
+	}
+	transcripts.LogAttrs(req.Context(), c.config.Logger, slog.LevelDebug, "http request", attrs...)
+	return resp, nil
+}
+
+// log logs through the connection's logger with the request ID of the websocket handshake.
+func (c *Conn) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if resp := c.conn.Response(); resp != nil {
+		if requestID := resp.Header.Get("request-id"); requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+	}
+	transcripts.LogAttrs(ctx, c.logger, level, msg, attrs...)
+}
@@ -0,0 +1,64 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
+)
+
+func TestClientLogsHTTPRequestsWithRequestID(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "req_42")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	cfg := DefaultConfig("secret-key")
+	cfg.BaseURL = server.URL
+	cfg.Logger = transcripts.NewSlogLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := NewClientWithConfig(cfg).Synthesize(context.Background(), SynthesisRequest{VoiceID: "voice_123", Text: "Hi."}); err != nil {
+		t.Fatal(err)
+	}
+
+	line := buf.String()
+	for _, want := range []string{`msg="http request"`, "method=POST", "status=200", "request_id=req_42", "/v1/text-to-speech/voice_123", "duration="} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log %q does not contain %q", line, want)
+		}
+	}
+	if strings.Contains(line, "secret-key") {
+		t.Errorf("log %q contains the API key", line)
+	}
+}
+
+func TestConnectRealtimeLogsRedactedURL(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	var buf bytes.Buffer
+	cfg := DefaultConfig("secret-key")
+	cfg.BaseURL = strings.Replace(server.URL, "http://", "http://user:secret-pass@", 1)
+	cfg.Logger = transcripts.NewSlogLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := NewClientWithConfig(cfg).ConnectRealtime(context.Background(), StreamInputRequest{VoiceID: "voice_123"}); err == nil {
+		t.Fatal("ConnectRealtime() to a plain HTTP server succeeded")
+	}
+
+	line := buf.String()
+	if !strings.Contains(line, `msg="websocket dial failed"`) || !strings.Contains(line, "REDACTED@") {
+		t.Errorf("log %q does not contain the redacted URL", line)
+	}
+	if strings.Contains(line, "secret-pass") {
+		t.Errorf("log %q contains the URL password", line)
+	}
+}
@@ -0,0 +1,314 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gouyuwang/go-elevenlabs/internal/mp3"
+)
+
+const (
+	// DefaultLongFormConcurrency is the number of chunks synthesized at the same time.
+	DefaultLongFormConcurrency = 4
+	// DefaultMaxCharacters is used when the model's limit cannot be looked up.
+	DefaultMaxCharacters = 5000
+	// maxStitchedRequests is the number of request IDs the API accepts as previous or next requests.
+	maxStitchedRequests = 3
+	// stitchContextChars is how much neighbouring text is sent as previous_text and next_text.
+	stitchContextChars = 500
+)
+
+// LongFormOptions configures SynthesizeLongForm.
+type LongFormOptions struct {
+	// MaxCharacters caps the chunk length. When zero, the limit of the request's model is looked up
+	// with ListModels, falling back to DefaultMaxCharacters.
+	MaxCharacters int
+	// Concurrency bounds the number of requests in flight. Defaults to DefaultLongFormConcurrency.
+	Concurrency int
+	// Concat joins the chunk audio. For MP3 output, the default, it defaults to joining the MP3 frames
+	// without tags and Xing/Info frames, like audio.ConcatMP3; the encoder delay and padding of each
+	// chunk remain. For PCM and G.711 output it defaults to byte concatenation. Opus output requires
+	// Concat, such as audio.ConcatOggOpus.
+	Concat func(w io.Writer, clips ...io.Reader) error
+	// OnChunk, when set, is called in text order as soon as each chunk and all chunks before it are done.
+	OnChunk func(chunk LongFormChunk)
+}
+
+// LongFormChunk is one synthesized piece of a long text.
+type LongFormChunk struct {
+	Index          int
+	Text           string
+	RequestID      string
+	CharacterCount string
+	Audio          []byte
+}
+
+// LongFormResponse is the result of SynthesizeLongForm.
+type LongFormResponse struct {
+	// Audio is the audio of all chunks, in order.
+	Audio       []byte
+	ContentType string
+	Chunks      []LongFormChunk
+
+	request SynthesisRequest
+	concat  func(w io.Writer, clips ...io.Reader) error
+}
+
+// SynthesizeLongForm synthesizes text longer than the model's character limit.
+//
+// The text is split with SplitText, and the chunks are synthesized by a bounded pool of workers.
+// Every request carries the neighbouring text as PreviousText and NextText. PreviousRequestIDs holds the
+// request IDs of up to three preceding chunks that completed before the chunk was sent, and NextRequestIDs
+// those of following chunks; with Concurrency 1 every chunk is stitched to its predecessors. Chunks are sent
+// in text order, so following chunks are only finished, and NextRequestIDs only set, when a chunk is sent
+// again with ResynthesizeChunk.
+//
+// The first failing chunk cancels the others and its error is returned.
+func (c *Client) SynthesizeLongForm(ctx context.Context, req SynthesisRequest, opts LongFormOptions) (*LongFormResponse, error) {
+	maxChars := opts.MaxCharacters
+	if maxChars <= 0 {
+		maxChars = c.modelMaxCharacters(ctx, req.ModelID)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultLongFormConcurrency
+	}
+	if opts.Concat == nil {
+		switch acceptHeader(req.OutputFormat) {
+		case "audio/mpeg":
+			opts.Concat = concatMP3
+		case "audio/ogg":
+			return nil, fmt.Errorf("long-form synthesis: %s output requires LongFormOptions.Concat", req.OutputFormat)
+		default:
+			opts.Concat = concatBytes
+		}
+	}
+
+	texts := SplitText(req.Text, maxChars)
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("long-form synthesis: empty text")
+	}
+	resp := &LongFormResponse{
+		Chunks:  make([]LongFormChunk, len(texts)),
+		request: req,
+		concat:  opts.Concat,
+	}
+	for i, text := range texts {
+		resp.Chunks[i] = LongFormChunk{Index: i, Text: text}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make([]chan struct{}, len(texts))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	jobs := make(chan int)
+	for w := 0; w < min(opts.Concurrency, len(texts)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				chunkReq := resp.chunkRequest(i, func(j int) bool {
+					select {
+					case <-done[j]:
+						return true
+					default:
+						return false
+					}
+				})
+				synthesized, err := c.Synthesize(ctx, chunkReq)
+				if err != nil {
+					fail(fmt.Errorf("synthesize chunk %d: %w", i, err))
+					continue
+				}
+				mu.Lock()
+				resp.setChunk(i, synthesized)
+				mu.Unlock()
+				close(done[i])
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range texts {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := range texts {
+		select {
+		case <-done[i]:
+			if opts.OnChunk != nil {
+				mu.Lock()
+				chunk := resp.Chunks[i]
+				mu.Unlock()
+				opts.OnChunk(chunk)
+			}
+		case <-ctx.Done():
+		}
+	}
+	cancel()
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil && !allClosed(done) {
+		return nil, err
+	}
+	if err := resp.join(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ResynthesizeChunk synthesizes chunk index again, stitched to the request IDs of up to three chunks on
+// each side, and rebuilds Audio. The response must come from SynthesizeLongForm.
+func (c *Client) ResynthesizeChunk(ctx context.Context, resp *LongFormResponse, index int) error {
+	if index < 0 || index >= len(resp.Chunks) {
+		return fmt.Errorf("chunk %d out of range", index)
+	}
+	req := resp.chunkRequest(index, func(int) bool { return true })
+	synthesized, err := c.Synthesize(ctx, req)
+	if err != nil {
+		return fmt.Errorf("synthesize chunk %d: %w", index, err)
+	}
+	resp.setChunk(index, synthesized)
+	return resp.join()
+}
+
+// chunkRequest builds the request for chunk i, stitched to the finished chunks on both sides.
+// finished reports whether another chunk is done.
+func (r *LongFormResponse) chunkRequest(i int, finished func(j int) bool) SynthesisRequest {
+	req := r.request
+	req.Text = r.Chunks[i].Text
+	req.PreviousText, req.NextText = "", ""
+	req.PreviousRequestIDs, req.NextRequestIDs = nil, nil
+	if i > 0 {
+		req.PreviousText = tail(r.Chunks[i-1].Text, stitchContextChars)
+	} else {
+		req.PreviousText = r.request.PreviousText
+	}
+	if i+1 < len(r.Chunks) {
+		req.NextText = head(r.Chunks[i+1].Text, stitchContextChars)
+	} else {
+		req.NextText = r.request.NextText
+	}
+	for j := max(0, i-maxStitchedRequests); j < i; j++ {
+		if finished(j) && r.Chunks[j].RequestID != "" {
+			req.PreviousRequestIDs = append(req.PreviousRequestIDs, r.Chunks[j].RequestID)
+		}
+	}
+	for j := i + 1; j < len(r.Chunks) && j <= i+maxStitchedRequests; j++ {
+		if finished(j) && r.Chunks[j].RequestID != "" {
+			req.NextRequestIDs = append(req.NextRequestIDs, r.Chunks[j].RequestID)
+		}
+	}
+	return req
+}
+
+func (r *LongFormResponse) setChunk(i int, synthesized *SynthesisResponse) {
+	chunk := &r.Chunks[i]
+	chunk.Audio = synthesized.Audio
+	chunk.RequestID = synthesized.RequestID
+	chunk.CharacterCount = synthesized.CharacterCount
+	if r.ContentType == "" {
+		r.ContentType = synthesized.ContentType
+	}
+}
+
+func (r *LongFormResponse) join() error {
+	clips := make([]io.Reader, len(r.Chunks))
+	for i, chunk := range r.Chunks {
+		clips[i] = bytes.NewReader(chunk.Audio)
+	}
+	var buf bytes.Buffer
+	if err := r.concat(&buf, clips...); err != nil {
+		return fmt.Errorf("join chunk audio: %w", err)
+	}
+	r.Audio = buf.Bytes()
+	return nil
+}
+
+// modelMaxCharacters looks up the character limit of a model.
+func (c *Client) modelMaxCharacters(ctx context.Context, modelID string) int {
+	if modelID == "" {
+		modelID = ModelElevenMultilingualV2
+	}
+	models, err := c.ListModels(ctx)
+	if err != nil {
+		return DefaultMaxCharacters
+	}
+	for _, model := range models {
+		if model.ModelID == modelID && model.MaxCharacters > 0 {
+			return model.MaxCharacters
+		}
+	}
+	return DefaultMaxCharacters
+}
+
+// errChunkFormat is returned when MP3 chunks differ in sample rate or channel count.
+var errChunkFormat = errors.New("tts: chunk audio format mismatch")
+
+// concatMP3 joins MP3 chunks like audio.ConcatMP3.
+func concatMP3(w io.Writer, clips ...io.Reader) error {
+	return mp3.Concat(w, errChunkFormat, clips...)
+}
+
+func concatBytes(w io.Writer, clips ...io.Reader) error {
+	for _, clip := range clips {
+		if _, err := io.Copy(w, clip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func allClosed(chans []chan struct{}) bool {
+	for _, ch := range chans {
+		select {
+		case <-ch:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func head(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:n]))
+}
+
+func tail(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return strings.TrimSpace(string(runes[len(runes)-n:]))
+}
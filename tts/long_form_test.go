@@ -0,0 +1,186 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gouyuwang/go-elevenlabs/internal/mp3"
+)
+
+func TestSplitTextPrefersParagraphsThenSentences(t *testing.T) {
+	t.Parallel()
+
+	text := "First paragraph is short.\n\nSecond one has two sentences. It is longer than the limit!\n\n" +
+		"Third. 中文句子。还有一句。"
+	got := SplitText(text, 40)
+	want := []string{
+		"First paragraph is short.",
+		"Second one has two sentences.",
+		"It is longer than the limit!",
+		"Third. 中文句子。还有一句。",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("SplitText() = %q, want %q", got, want)
+	}
+
+	for _, chunk := range SplitText(strings.Repeat("word ", 50)+strings.Repeat("x", 30), 12) {
+		if n := utf8.RuneCountInString(chunk); n > 12 {
+			t.Errorf("chunk %q has %d characters", chunk, n)
+		}
+	}
+	if got, want := SplitText(`He said "stop." Then left.`, 20), []string{`He said "stop."`, "Then left."}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("SplitText() = %q, want %q", got, want)
+	}
+}
+
+func TestClientSynthesizeLongFormStitchesChunks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests = map[string]map[string]any{}
+		counter  atomic.Int64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/models" {
+			_, _ = w.Write([]byte(`[{"model_id":"eleven_turbo_v2_5","max_characters":40}]`))
+			return
+		}
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		text := payload["text"].(string)
+		mu.Lock()
+		requests[text] = payload
+		mu.Unlock()
+		w.Header().Set("request-id", fmt.Sprintf("req_%d", counter.Add(1)))
+		w.Header().Set("Content-Type", "audio/pcm")
+		_, _ = w.Write([]byte("<" + text + ">"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(ClientConfig{authKey: "test-key", BaseURL: server.URL})
+	var order []int
+	resp, err := client.SynthesizeLongForm(context.Background(), SynthesisRequest{
+		VoiceID:      "voice_123",
+		ModelID:      ModelElevenTurboV25,
+		Text:         "One sentence here. Two sentence here. Three sentence here. Four sentence here.",
+		OutputFormat: AudioFormatPCM16000,
+	}, LongFormOptions{
+		Concurrency: 1,
+		OnChunk:     func(chunk LongFormChunk) { order = append(order, chunk.Index) },
+	})
+	if err != nil {
+		t.Fatalf("SynthesizeLongForm() error = %v", err)
+	}
+
+	if got, want := len(resp.Chunks), 2; got != want {
+		t.Fatalf("len(Chunks) = %d, want %d", got, want)
+	}
+	if got, want := string(resp.Audio), "<One sentence here. Two sentence here.><Three sentence here. Four sentence here.>"; got != want {
+		t.Errorf("Audio = %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprint(order), "[0 1]"; got != want {
+		t.Errorf("OnChunk order = %s, want %s", got, want)
+	}
+	second := requests[resp.Chunks[1].Text]
+	if got, want := second["previous_text"], resp.Chunks[0].Text; got != want {
+		t.Errorf("previous_text = %v, want %v", got, want)
+	}
+	if got, want := fmt.Sprint(second["previous_request_ids"]), "[req_1]"; got != want {
+		t.Errorf("previous_request_ids = %s, want %s", got, want)
+	}
+	if got, want := requests[resp.Chunks[0].Text]["next_text"], resp.Chunks[1].Text; got != want {
+		t.Errorf("next_text = %v, want %v", got, want)
+	}
+
+	if err = client.ResynthesizeChunk(context.Background(), resp, 0); err != nil {
+		t.Fatalf("ResynthesizeChunk() error = %v", err)
+	}
+	if got, want := fmt.Sprint(requests[resp.Chunks[0].Text]["next_request_ids"]), "[req_2]"; got != want {
+		t.Errorf("next_request_ids = %s, want %s", got, want)
+	}
+	if got, want := resp.Chunks[0].RequestID, "req_3"; got != want {
+		t.Errorf("RequestID = %s, want %s", got, want)
+	}
+}
+
+func TestClientSynthesizeLongFormReturnsFirstError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if strings.HasPrefix(payload["text"].(string), "Bad") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"detail":{"message":"bad chunk"}}`))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(ClientConfig{authKey: "test-key", BaseURL: server.URL})
+	_, err := client.SynthesizeLongForm(context.Background(), SynthesisRequest{
+		VoiceID: "voice_123",
+		Text:    "Good one.\n\nBad one.\n\nGood two.",
+	}, LongFormOptions{MaxCharacters: 10})
+	if err == nil || !strings.Contains(err.Error(), "chunk 1") {
+		t.Fatalf("SynthesizeLongForm() error = %v, want chunk 1 failure", err)
+	}
+}
+
+func TestClientSynthesizeLongFormJoinsMP3Frames(t *testing.T) {
+	t.Parallel()
+
+	sample, err := os.ReadFile("../examples/simple/nicole.mp3")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	reader := mp3.NewReader(bytes.NewReader(sample))
+	var frames, info []byte
+	for range 3 {
+		frame, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if info == nil {
+			info = bytes.Clone(frame.Data)
+			copy(info[4+17:], "Info")
+		}
+		frames = append(frames, frame.Data...)
+	}
+	clip := append(append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), info...), frames...)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write(clip)
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(ClientConfig{authKey: "test-key", BaseURL: server.URL})
+	req := SynthesisRequest{VoiceID: "voice_123", Text: "Good one.\n\nGood two."}
+	resp, err := client.SynthesizeLongForm(context.Background(), req, LongFormOptions{MaxCharacters: 10})
+	if err != nil {
+		t.Fatalf("SynthesizeLongForm() error = %v", err)
+	}
+	if want := bytes.Repeat(frames, 2); !bytes.Equal(resp.Audio, want) {
+		t.Errorf("Audio = %d bytes, want %d bytes of audio frames", len(resp.Audio), len(want))
+	}
+
+	req.OutputFormat = AudioFormatOpus4800064
+	if _, err = client.SynthesizeLongForm(context.Background(), req, LongFormOptions{MaxCharacters: 10}); err == nil {
+		t.Error("SynthesizeLongForm() error = nil for Opus output without Concat")
+	}
+}
@@ -14,7 +14,7 @@ func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
 	}
 	req.Header.Set("xi-api-key", c.config.authKey)
 
-	resp, err := c.httpClient().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
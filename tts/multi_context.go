@@ -0,0 +1,120 @@
+package tts
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// contextMessage is a control or initialization message of the multi-context endpoint.
+type contextMessage struct {
+	Text                            string                           `json:"text,omitempty"`
+	ContextID                       string                           `json:"context_id,omitempty"`
+	VoiceSettings                   *VoiceSettings                   `json:"voice_settings,omitempty"`
+	GenerationConfig                *GenerationConfig                `json:"generation_config,omitempty"`
+	PronunciationDictionaryLocators []PronunciationDictionaryLocator `json:"pronunciation_dictionary_locators,omitempty"`
+	Flush                           bool                             `json:"flush,omitempty"`
+	CloseContext                    bool                             `json:"close_context,omitempty"`
+	CloseSocket                     bool                             `json:"close_socket,omitempty"`
+}
+
+// ConnectMultiContext opens a websocket TTS session on the multi-stream-input endpoint, which
+// generates several independent contexts over one connection. A Streamer on this connection sends
+// its text to a current context and opens a new one on Interrupt, closing the old one so that the
+// server stops generating it. Audio, final and error events carry the ContextID they belong to.
+// The endpoint takes the model and language as query parameters. As with ConnectRealtime, a
+// rejection arrives with the first ReadEvent unless WithReadyTimeout is set.
+func (c *Client) ConnectMultiContext(ctx context.Context, req StreamInputRequest, opts ...ConnectOption) (*Conn, error) {
+	uri, err := c.streamInputURL(req, "multi-stream-input")
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	if req.ModelID != "" {
+		query.Set("model_id", req.ModelID)
+	}
+	if req.LanguageCode != "" {
+		query.Set("language_code", req.LanguageCode)
+	}
+	u.RawQuery = query.Encode()
+	conn, readyTimeout, err := c.dialRealtime(ctx, u.String(), opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.awaitReady(ctx, readyTimeout); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	conn.multiContext = true
+	conn.contextInit = contextMessage{
+		Text:                            " ",
+		VoiceSettings:                   req.VoiceSettings,
+		GenerationConfig:                req.GenerationConfig,
+		PronunciationDictionaryLocators: req.PronunciationDictionaryLocators,
+	}
+	return conn, nil
+}
+
+// MultiContext reports whether the connection was opened with ConnectMultiContext.
+func (c *Conn) MultiContext() bool {
+	return c.multiContext
+}
+
+// FlushContext asks the server to generate audio for the text buffered in a context.
+func (c *Conn) FlushContext(ctx context.Context, contextID string) error {
+	return c.Send(ctx, contextMessage{ContextID: contextID, Flush: true})
+}
+
+// CloseContext stops the generation of a context and discards its buffered text. The server ends
+// the context with a final event; the connection stays open.
+func (c *Conn) CloseContext(ctx context.Context, contextID string) error {
+	return c.Send(ctx, contextMessage{ContextID: contextID, CloseContext: true})
+}
+
+// ContextID returns the context that text is sent to on a multi-context connection. It is empty
+// before the first message and on a stream-input connection.
+func (s *Streamer) ContextID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.context
+}
+
+// openContext returns the current context and sends its initialization message first if needed.
+// The caller holds writeMu.
+func (s *Streamer) openContext() (string, error) {
+	s.mu.Lock()
+	if s.context == "" {
+		s.nextContextLocked()
+	}
+	id, open := s.context, s.contextOpen
+	s.contextOpen = true
+	s.mu.Unlock()
+	if open {
+		return id, nil
+	}
+	init := s.conn.contextInit
+	init.ContextID = id
+	return id, s.conn.Send(s.ctx, init)
+}
+
+// nextContextLocked switches to a new context that is opened by the next message.
+func (s *Streamer) nextContextLocked() {
+	s.contextSeq++
+	s.context = "ctx_" + strconv.Itoa(s.contextSeq)
+	s.contextOpen = false
+}
+
+// finalEvent reports whether done ends the session. On a multi-context connection only the final
+// event of the current context after CloseInput does; other contexts end while the session goes on.
+func (s *Streamer) finalEvent(done DoneEvent) bool {
+	if !s.conn.multiContext {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.State() >= StreamerStateInputClosed && done.ContextID == s.context
+}
@@ -0,0 +1,261 @@
+package tts
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxBreakDuration is the longest pause a <break> tag may request.
+const MaxBreakDuration = 3 * time.Second
+
+// PhonemeAlphabet is the notation of a <phoneme> tag.
+type PhonemeAlphabet string
+
+const (
+	PhonemeAlphabetIPA        PhonemeAlphabet = "ipa"
+	PhonemeAlphabetCMUArpabet PhonemeAlphabet = "cmu-arpabet"
+)
+
+type ssmlSupport struct {
+	breaks   bool
+	phonemes bool
+}
+
+// ssmlModelSupport lists the SSML tags each model understands. Models not listed are not checked.
+// Eleven v3 takes audio tags such as [pause] instead of SSML.
+var ssmlModelSupport = map[string]ssmlSupport{
+	ModelElevenV3:             {},
+	ModelElevenMultilingualV2: {breaks: true},
+	ModelElevenTurboV25:       {breaks: true},
+	ModelElevenFlashV25:       {breaks: true},
+	"eleven_turbo_v2":         {breaks: true, phonemes: true},
+	"eleven_flash_v2":         {breaks: true, phonemes: true},
+	"eleven_monolingual_v1":   {breaks: true, phonemes: true},
+}
+
+var (
+	ssmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	ssmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+)
+
+// SSMLBuilder builds escaped SSML for SynthesisRequest.Text and StreamTextMessage.Text.
+// The zero value is ready to use.
+type SSMLBuilder struct {
+	b strings.Builder
+}
+
+// NewSSMLBuilder returns an empty SSMLBuilder.
+func NewSSMLBuilder() *SSMLBuilder {
+	return &SSMLBuilder{}
+}
+
+// Text appends plain text. Markup characters are escaped.
+func (s *SSMLBuilder) Text(text string) *SSMLBuilder {
+	s.b.WriteString(ssmlTextEscaper.Replace(text))
+	return s
+}
+
+// Break appends a pause.
+func (s *SSMLBuilder) Break(d time.Duration) *SSMLBuilder {
+	s.b.WriteString(`<break time="` + formatBreakTime(d) + `" />`)
+	return s
+}
+
+// Phoneme appends a word with an explicit pronunciation.
+func (s *SSMLBuilder) Phoneme(alphabet PhonemeAlphabet, phonemes, word string) *SSMLBuilder {
+	fmt.Fprintf(&s.b, `<phoneme alphabet="%s" ph="%s">%s</phoneme>`,
+		ssmlAttrEscaper.Replace(string(alphabet)), ssmlAttrEscaper.Replace(phonemes), ssmlTextEscaper.Replace(word))
+	return s
+}
+
+// String returns the markup built so far without validating it.
+func (s *SSMLBuilder) String() string {
+	return s.b.String()
+}
+
+// Build returns the markup after checking it with ValidateSSML for modelID.
+func (s *SSMLBuilder) Build(modelID string) (string, error) {
+	text := s.String()
+	if err := ValidateSSML(text, modelID); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+func formatBreakTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Round(time.Millisecond).Seconds(), 'f', -1, 64) + "s"
+}
+
+// SSMLIssue is one problem found by ValidateSSML.
+type SSMLIssue struct {
+	// Offset is the byte offset in the validated text.
+	Offset  int64
+	Tag     string
+	Message string
+}
+
+func (i SSMLIssue) String() string {
+	if i.Tag == "" {
+		return fmt.Sprintf("offset %d: %s", i.Offset, i.Message)
+	}
+	return fmt.Sprintf("offset %d: <%s>: %s", i.Offset, i.Tag, i.Message)
+}
+
+// SSMLError lists the problems found by ValidateSSML.
+type SSMLError struct {
+	Issues []SSMLIssue
+}
+
+func (e *SSMLError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = issue.String()
+	}
+	return "invalid SSML: " + strings.Join(parts, "; ")
+}
+
+// ValidateSSML checks markup for the tags ElevenLabs understands: <break> durations up to MaxBreakDuration,
+// <phoneme> with a known alphabet around a single word, no other or nested tags, and tags the model
+// supports. An empty modelID means the default model, eleven_multilingual_v2. A <speak> root is allowed.
+// It returns an *SSMLError.
+func ValidateSSML(text, modelID string) error {
+	if modelID == "" {
+		modelID = ModelElevenMultilingualV2
+	}
+	support, knownModel := ssmlModelSupport[modelID]
+
+	const root = "<speak>"
+	decoder := xml.NewDecoder(strings.NewReader(root + text + "</speak>"))
+	decoder.Strict = true
+	var (
+		issues []SSMLIssue
+		stack  []string
+		// phonemeText collects the content of the open <phoneme> tag.
+		phonemeText strings.Builder
+	)
+	add := func(offset int64, tag, format string, args ...any) {
+		issues = append(issues, SSMLIssue{Offset: max(0, offset-int64(len(root))), Tag: tag, Message: fmt.Sprintf(format, args...)})
+	}
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			add(offset, "", "malformed markup: %v", err)
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			if len(stack) == 0 {
+				// The wrapper added above.
+				stack = append(stack, name)
+				continue
+			}
+			switch parent := stack[len(stack)-1]; {
+			case name == "speak" && len(stack) > 1:
+				add(offset, name, "only allowed as the root element")
+			case parent != "speak":
+				add(offset, name, "cannot be nested inside <%s>", parent)
+			}
+			stack = append(stack, name)
+			switch name {
+			case "speak":
+			case "break":
+				if knownModel && !support.breaks {
+					add(offset, name, "not supported by model %s", modelID)
+				}
+				validateBreak(t, func(format string, args ...any) { add(offset, name, format, args...) })
+			case "phoneme":
+				if knownModel && !support.phonemes {
+					add(offset, name, "not supported by model %s", modelID)
+				}
+				phonemeText.Reset()
+				validatePhoneme(t, func(format string, args ...any) { add(offset, name, format, args...) })
+			default:
+				add(offset, name, "unsupported tag")
+			}
+		case xml.EndElement:
+			if t.Name.Local == "phoneme" {
+				word := strings.TrimSpace(phonemeText.String())
+				if word == "" || strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+					add(offset, "phoneme", "must contain exactly one word")
+				}
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				switch stack[len(stack)-1] {
+				case "phoneme":
+					phonemeText.Write(t)
+				case "break":
+					if strings.TrimSpace(string(t)) != "" {
+						add(offset, "break", "must be empty")
+					}
+				}
+			}
+		}
+	}
+	if len(issues) > 0 {
+		return &SSMLError{Issues: issues}
+	}
+	return nil
+}
+
+func validateBreak(element xml.StartElement, report func(format string, args ...any)) {
+	value, ok := attr(element, "time")
+	if !ok {
+		report(`missing "time" attribute`)
+		return
+	}
+	d, err := parseBreakTime(value)
+	switch {
+	case err != nil:
+		report("invalid time %q, want a value such as 1.5s or 500ms", value)
+	case d <= 0:
+		report("time %q must be positive", value)
+	case d > MaxBreakDuration:
+		report("time %q exceeds the %v maximum", value, MaxBreakDuration)
+	}
+}
+
+func validatePhoneme(element xml.StartElement, report func(format string, args ...any)) {
+	alphabet, _ := attr(element, "alphabet")
+	switch PhonemeAlphabet(alphabet) {
+	case PhonemeAlphabetIPA, PhonemeAlphabetCMUArpabet:
+	default:
+		report("unsupported alphabet %q", alphabet)
+	}
+	if ph, _ := attr(element, "ph"); strings.TrimSpace(ph) == "" {
+		report(`missing "ph" attribute`)
+	}
+}
+
+func parseBreakTime(value string) (time.Duration, error) {
+	if number, ok := strings.CutSuffix(value, "ms"); ok {
+		ms, err := strconv.ParseFloat(number, 64)
+		return time.Duration(ms * float64(time.Millisecond)), err
+	}
+	if number, ok := strings.CutSuffix(value, "s"); ok {
+		seconds, err := strconv.ParseFloat(number, 64)
+		return time.Duration(seconds * float64(time.Second)), err
+	}
+	return 0, fmt.Errorf("missing unit")
+}
+
+func attr(element xml.StartElement, name string) (string, bool) {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
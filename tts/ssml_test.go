@@ -0,0 +1,63 @@
+package tts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSMLBuilderEscapesText(t *testing.T) {
+	t.Parallel()
+
+	got, err := NewSSMLBuilder().
+		Text(`Tom & Jerry say "<hi>". `).
+		Break(1500*time.Millisecond).
+		Text("Say ").
+		Phoneme(PhonemeAlphabetCMUArpabet, "M AE1 D IH0 S AH0 N", "Madison").
+		Build("eleven_flash_v2")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := `Tom &amp; Jerry say "&lt;hi&gt;". <break time="1.5s" />Say ` +
+		`<phoneme alphabet="cmu-arpabet" ph="M AE1 D IH0 S AH0 N">Madison</phoneme>`
+	if got != want {
+		t.Errorf("Build() = %s, want %s", got, want)
+	}
+}
+
+func TestValidateSSMLReportsIssues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		text    string
+		modelID string
+		want    string
+	}{
+		{name: "valid with speak root", text: `<speak>Hello <break time="500ms"/> there.</speak>`},
+		{name: "too long break", text: `Wait <break time="5s" />`, want: "exceeds"},
+		{name: "bad break unit", text: `<break time="2 seconds" />`, want: "invalid time"},
+		{name: "unsupported tag", text: `<prosody rate="slow">hi</prosody>`, want: "unsupported tag"},
+		{name: "nested", text: `<phoneme alphabet="ipa" ph="x">a<break time="1s"/></phoneme>`, modelID: "eleven_turbo_v2", want: "cannot be nested"},
+		{name: "phoneme on multilingual", text: `<phoneme alphabet="ipa" ph="ˈæktʃuəli">actually</phoneme>`, want: "not supported by model eleven_multilingual_v2"},
+		{name: "phoneme phrase", text: `<phoneme alphabet="ipa" ph="x">two words</phoneme>`, modelID: "eleven_flash_v2", want: "exactly one word"},
+		{name: "break on v3", text: `a <break time="1s" /> b`, modelID: ModelElevenV3, want: "not supported by model eleven_v3"},
+		{name: "unescaped ampersand", text: `Tom & Jerry`, want: "malformed markup"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSSML(tt.text, tt.modelID)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("ValidateSSML() error = %v", err)
+				}
+				return
+			}
+			var ssmlErr *SSMLError
+			if !errors.As(err, &ssmlErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ValidateSSML() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package tts
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var paragraphBreak = regexp.MustCompile(`\n[ \t\r]*\n\s*`)
+
+type splitLevel int
+
+const (
+	splitParagraph splitLevel = iota
+	splitSentence
+	splitClause
+	splitWord
+	splitRune
+)
+
+// SplitText splits text into chunks of at most maxChars characters. It breaks at paragraph boundaries
+// where possible, then at sentence ends, clause punctuation and whitespace, and cuts words only as a last
+// resort. Chunks are trimmed of surrounding whitespace.
+func SplitText(text string, maxChars int) []string {
+	if maxChars <= 0 {
+		maxChars = 1
+	}
+	var chunks []string
+	for _, piece := range splitAt(text, maxChars, splitParagraph) {
+		if piece = strings.TrimSpace(piece); piece != "" {
+			chunks = append(chunks, piece)
+		}
+	}
+	return chunks
+}
+
+func splitAt(text string, maxChars int, level splitLevel) []string {
+	if utf8.RuneCountInString(strings.TrimSpace(text)) <= maxChars {
+		return []string{text}
+	}
+	if level == splitRune {
+		return cutRunes(strings.TrimSpace(text), maxChars)
+	}
+
+	var (
+		pieces  []string
+		current strings.Builder
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			pieces = append(pieces, current.String())
+			current.Reset()
+		}
+	}
+	for _, segment := range segments(text, level) {
+		switch {
+		case utf8.RuneCountInString(strings.TrimSpace(current.String()+segment)) <= maxChars:
+			current.WriteString(segment)
+		case utf8.RuneCountInString(strings.TrimSpace(segment)) <= maxChars:
+			flush()
+			current.WriteString(segment)
+		default:
+			flush()
+			pieces = append(pieces, splitAt(segment, maxChars, level+1)...)
+		}
+	}
+	flush()
+	return pieces
+}
+
+// segments cuts text at the boundaries of the given level. Joining the segments gives back text.
+func segments(text string, level splitLevel) []string {
+	var cuts []int
+	switch level {
+	case splitParagraph:
+		for _, match := range paragraphBreak.FindAllStringIndex(text, -1) {
+			cuts = append(cuts, match[1])
+		}
+	default:
+		runes := []rune(text)
+		offset := 0
+		for i, r := range runes {
+			offset += utf8.RuneLen(r)
+			var prev, next rune
+			if i > 0 {
+				prev = runes[i-1]
+			}
+			if i+1 < len(runes) {
+				next = runes[i+1]
+			}
+			if isBoundary(prev, r, next, level) {
+				cuts = append(cuts, offset)
+			}
+		}
+	}
+
+	var out []string
+	start := 0
+	for _, cut := range cuts {
+		if cut > start && cut < len(text) {
+			out = append(out, text[start:cut])
+			start = cut
+		}
+	}
+	return append(out, text[start:])
+}
+
+// isBoundary reports whether text may be cut between r and next. A closing quote or bracket
+// stays with the punctuation before it.
+func isBoundary(prev, r, next rune, level splitLevel) bool {
+	followedBySpace := next == 0 || unicode.IsSpace(next)
+	ends := func(punctuation string) bool {
+		return strings.ContainsRune(punctuation, r) ||
+			(strings.ContainsRune(`"'”’)]」』`, r) && strings.ContainsRune(punctuation, prev))
+	}
+	switch level {
+	case splitSentence:
+		return (ends("。！？") && !strings.ContainsRune(`"'”’)]」』`, next)) || (ends(".!?…") && followedBySpace)
+	case splitClause:
+		return ends("，；：、") || (ends(",;:—") && followedBySpace)
+	case splitWord:
+		return unicode.IsSpace(r)
+	default:
+		return false
+	}
+}
+
+func cutRunes(text string, maxChars int) []string {
+	runes := []rune(text)
+	var out []string
+	for len(runes) > 0 {
+		n := min(len(runes), maxChars)
+		out = append(out, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return out
+}
@@ -0,0 +1,235 @@
+package tts
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultFirstChunkLength is the length after which the first chunk is cut at a word boundary.
+	DefaultFirstChunkLength = 30
+	// DefaultMinChunkLength is the length a later chunk needs before it is cut at a sentence end.
+	DefaultMinChunkLength = 40
+	// DefaultMaxChunkLength is the length after which a chunk is cut at a word boundary.
+	DefaultMaxChunkLength = 200
+)
+
+// TokenStreamOptions configures SendTokens and SendTokensFrom. Lengths are in characters.
+type TokenStreamOptions struct {
+	// FirstChunkLength bounds the first chunk, which is also cut at the first sentence or clause end
+	// so that audio starts early. Defaults to DefaultFirstChunkLength.
+	FirstChunkLength int
+	// MinChunkLength is the shortest later chunk cut at a sentence end. Shorter sentences are joined
+	// with the next one. Defaults to DefaultMinChunkLength.
+	MinChunkLength int
+	// MaxChunkLength forces a cut at a clause end after half this length and at a word boundary
+	// after this length. Defaults to DefaultMaxChunkLength.
+	MaxChunkLength int
+	// IdleFlush sends the complete words buffered so far when no token arrived for this long,
+	// for example while an LLM runs a tool call. Zero disables it. Only used by SendTokens.
+	IdleFlush time.Duration
+}
+
+// SendTokens reads text deltas, such as LLM tokens, until tokens is closed, and sends them as speakable
+// chunks cut at sentence, clause and word boundaries. Chunks that end a sentence or clause are sent with
+// TryTriggerGeneration; chunks forced at a word boundary are not. When tokens is closed, the remaining
+// text is sent and the stream is flushed.
+func (s *Streamer) SendTokens(tokens <-chan string, opts TokenStreamOptions) error {
+	chunker := newTokenChunker(opts)
+	var (
+		idle  *time.Timer
+		idleC <-chan time.Time
+	)
+	if opts.IdleFlush > 0 {
+		idle = time.NewTimer(opts.IdleFlush)
+		defer idle.Stop()
+		idleC = idle.C
+	}
+	for {
+		select {
+		case token, ok := <-tokens:
+			if !ok {
+				return s.finishTokens(chunker)
+			}
+			if err := s.sendChunks(chunker.add(token)); err != nil {
+				return err
+			}
+			if idle != nil {
+				idle.Reset(opts.IdleFlush)
+			}
+		case <-idleC:
+			if err := s.sendChunks(chunker.words()); err != nil {
+				return err
+			}
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+	}
+}
+
+// SendTokensFrom is SendTokens for text read from r until EOF.
+func (s *Streamer) SendTokensFrom(r io.Reader, opts TokenStreamOptions) error {
+	chunker := newTokenChunker(opts)
+	var (
+		buf     = make([]byte, 512)
+		partial []byte
+	)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			// Hold back an incomplete UTF-8 sequence until the next read.
+			data := append(partial, buf[:n]...)
+			complete := len(data)
+			for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+				if utf8.RuneStart(data[i]) {
+					if !utf8.FullRune(data[i:]) {
+						complete = i
+					}
+					break
+				}
+			}
+			partial = append([]byte(nil), data[complete:]...)
+			if sendErr := s.sendChunks(chunker.add(string(data[:complete]))); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			if err := s.sendChunks(chunker.add(string(partial))); err != nil {
+				return err
+			}
+			return s.finishTokens(chunker)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Streamer) finishTokens(chunker *tokenChunker) error {
+	if err := s.sendChunks(chunker.rest()); err != nil {
+		return err
+	}
+	return s.Flush()
+}
+
+func (s *Streamer) sendChunks(chunks []textChunk) error {
+	for _, chunk := range chunks {
+		msg := StreamTextMessage{Text: chunk.text + " "}
+		if chunk.trigger {
+			trigger := true
+			msg.TryTriggerGeneration = &trigger
+		}
+		if err := s.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type textChunk struct {
+	text    string
+	trigger bool
+}
+
+// tokenChunker buffers text deltas and cuts them into speakable chunks.
+type tokenChunker struct {
+	opts TokenStreamOptions
+	buf  []rune
+	sent bool
+}
+
+func newTokenChunker(opts TokenStreamOptions) *tokenChunker {
+	if opts.FirstChunkLength <= 0 {
+		opts.FirstChunkLength = DefaultFirstChunkLength
+	}
+	if opts.MinChunkLength <= 0 {
+		opts.MinChunkLength = DefaultMinChunkLength
+	}
+	if opts.MaxChunkLength <= 0 {
+		opts.MaxChunkLength = DefaultMaxChunkLength
+	}
+	return &tokenChunker{opts: opts}
+}
+
+func (c *tokenChunker) add(token string) []textChunk {
+	c.buf = append(c.buf, []rune(token)...)
+	var chunks []textChunk
+	for {
+		cut, trigger := c.nextCut()
+		if cut < 0 {
+			return chunks
+		}
+		chunks = append(chunks, c.take(cut, trigger)...)
+	}
+}
+
+// nextCut returns the end of the next chunk, or -1 if more text is needed.
+// A boundary only counts once the rune after it is known.
+func (c *tokenChunker) nextCut() (int, bool) {
+	lastWord := -1
+	for i := 0; i+1 < len(c.buf); i++ {
+		var prev rune
+		if i > 0 {
+			prev = c.buf[i-1]
+		}
+		r, next := c.buf[i], c.buf[i+1]
+		length := i + 1
+		sentence := isBoundary(prev, r, next, splitSentence)
+		clause := isBoundary(prev, r, next, splitClause)
+		if !c.sent {
+			if sentence || clause {
+				return length, true
+			}
+		} else {
+			if sentence && length >= c.opts.MinChunkLength {
+				return length, true
+			}
+			if clause && length >= c.opts.MaxChunkLength/2 {
+				return length, true
+			}
+		}
+		if unicode.IsSpace(next) && !unicode.IsSpace(r) {
+			lastWord = length
+		}
+		limit := c.opts.MaxChunkLength
+		if !c.sent {
+			limit = c.opts.FirstChunkLength
+		}
+		if length >= limit && lastWord > 0 {
+			return lastWord, false
+		}
+	}
+	return -1, false
+}
+
+// words returns the complete words buffered so far.
+func (c *tokenChunker) words() []textChunk {
+	for i := len(c.buf) - 1; i > 0; i-- {
+		if unicode.IsSpace(c.buf[i]) {
+			return c.take(i, false)
+		}
+	}
+	return nil
+}
+
+// rest returns everything still buffered.
+func (c *tokenChunker) rest() []textChunk {
+	return c.take(len(c.buf), true)
+}
+
+func (c *tokenChunker) take(n int, trigger bool) []textChunk {
+	text := strings.TrimSpace(string(c.buf[:n]))
+	c.buf = append(c.buf[:0], c.buf[n:]...)
+	for len(c.buf) > 0 && unicode.IsSpace(c.buf[0]) {
+		c.buf = c.buf[1:]
+	}
+	if text == "" {
+		return nil
+	}
+	c.sent = true
+	return []textChunk{{text: text, trigger: trigger}}
+}
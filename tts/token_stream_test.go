@@ -0,0 +1,159 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func TestTokenChunkerCutsAtSpeakableBoundaries(t *testing.T) {
+	t.Parallel()
+
+	chunker := newTokenChunker(TokenStreamOptions{MinChunkLength: 20, MaxChunkLength: 60})
+	tokens := []string{"Sure", ",", " I", " can", " help", ".", " Your", " order", " ships", " today", ".",
+		" It", " arrives", " Monday", ".", " Anything", " else", "?"}
+	var got []textChunk
+	for _, token := range tokens {
+		got = append(got, chunker.add(token)...)
+	}
+	got = append(got, chunker.rest()...)
+
+	want := []textChunk{
+		{text: "Sure,", trigger: true},
+		{text: "I can help. Your order ships today.", trigger: true},
+		{text: "It arrives Monday. Anything else?", trigger: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunks = %+v, want %+v", got, want)
+	}
+}
+
+func TestTokenChunkerForcesWordBoundaryCut(t *testing.T) {
+	t.Parallel()
+
+	chunker := newTokenChunker(TokenStreamOptions{FirstChunkLength: 10})
+	got := chunker.add("one two three four five")
+	want := []textChunk{{text: "one two"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunks = %+v, want %+v", got, want)
+	}
+	if got, want := chunker.words(), []textChunk{{text: "three four"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("words() = %+v, want %+v", got, want)
+	}
+	if got, want := chunker.rest(), []textChunk{{text: "five", trigger: true}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rest() = %+v, want %+v", got, want)
+	}
+}
+
+type receivedTokenMessage struct {
+	Text                 string `json:"text"`
+	TryTriggerGeneration *bool  `json:"try_trigger_generation,omitempty"`
+	Flush                *bool  `json:"flush,omitempty"`
+}
+
+// sendTokensFrom runs SendTokensFrom against a test server and returns the messages after the
+// initialization message.
+func sendTokensFrom(t *testing.T, input string) []receivedTokenMessage {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		messages []receivedTokenMessage
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		ctx := r.Context()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			var msg receivedTokenMessage
+			if err = json.Unmarshal(data, &msg); err != nil {
+				t.Errorf("unmarshal message: %v", err)
+				return
+			}
+			mu.Lock()
+			messages = append(messages, msg)
+			mu.Unlock()
+			if msg.Flush != nil && *msg.Flush {
+				_ = conn.Write(ctx, websocket.MessageText, []byte(`{"isFinal":true}`))
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	conn, err := NewClientWithConfig(cfg).ConnectRealtime(context.Background(), StreamInputRequest{VoiceID: "voice_123"})
+	if err != nil {
+		t.Fatalf("ConnectRealtime() error = %v", err)
+	}
+	defer conn.Close()
+
+	streamer := NewRealtimeSynthesizer(context.Background(), conn)
+	streamer.Start()
+	if err = streamer.SendTokensFrom(strings.NewReader(input), TokenStreamOptions{}); err != nil {
+		t.Fatalf("SendTokensFrom() error = %v", err)
+	}
+	select {
+	case err = <-streamer.Err():
+		if err != nil {
+			t.Fatalf("streamer error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for streamer completion")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return messages[1:]
+}
+
+func TestStreamerSendTokensFromFlushesAtEOF(t *testing.T) {
+	t.Parallel()
+
+	messages := sendTokensFrom(t, "Hello there. Ça va?")
+	var texts []string
+	for _, msg := range messages {
+		texts = append(texts, msg.Text)
+	}
+	if got, want := texts, []string{"Hello there. ", "Ça va? ", ""}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("texts = %q, want %q", got, want)
+	}
+	if trigger := messages[0].TryTriggerGeneration; trigger == nil || !*trigger {
+		t.Error("sentence chunk should set try_trigger_generation")
+	}
+	if flush := messages[len(messages)-1].Flush; flush == nil || !*flush {
+		t.Error("last message should be a flush")
+	}
+}
+
+func TestStreamerSendTokensFromSendsChunksCompletedAtEOF(t *testing.T) {
+	t.Parallel()
+
+	// The reader ends at a sentence boundary followed by an incomplete rune. The rune is held back
+	// until EOF, and only then is the boundary known.
+	var texts []string
+	for _, msg := range sendTokensFrom(t, "你好。\xe4") {
+		texts = append(texts, msg.Text)
+	}
+	if got, want := texts, []string{"你好。 ", "\ufffd ", ""}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("texts = %q, want %q", got, want)
+	}
+}
@@ -85,6 +85,10 @@ type SynthesisResponse struct {
 	RequestID      string
 	CharacterCount string
 	Headers        http.Header
+	// Cached reports whether the audio was served by a CachedClient from its cache.
+	Cached bool
+	// Latency is nil for cached responses.
+	Latency *RequestLatency
 }
 
 // StreamResponse is the response for HTTP audio streaming.
@@ -95,6 +99,10 @@ type StreamResponse struct {
 	RequestID      string
 	CharacterCount string
 	Headers        http.Header
+	// Cached reports whether the audio was served by a CachedClient from its cache.
+	Cached bool
+	// Latency is nil for cached responses.
+	Latency *RequestLatency
 }
 
 type APIError struct {
@@ -5,10 +5,15 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/coder/websocket"
 	"github.com/gouyuwang/go-elevenlabs/transcripts"
@@ -38,21 +43,43 @@ type StreamTextMessage struct {
 	Flush                           *bool                            `json:"flush,omitempty"`
 	GenerationConfig                *GenerationConfig                `json:"generation_config,omitempty"`
 	PronunciationDictionaryLocators []PronunciationDictionaryLocator `json:"pronunciation_dictionary_locators,omitempty"`
+	// ContextID selects the context on a multi-context connection. Streamer sets it.
+	ContextID string `json:"context_id,omitempty"`
 }
 
 type StreamEvent interface{}
 
 type AudioEvent struct {
-	Audio   []byte
-	IsFinal bool
+	Audio               []byte
+	IsFinal             bool
+	Alignment           *Alignment
+	NormalizedAlignment *Alignment
+	// ContextID is the context the audio belongs to on a multi-context connection.
+	ContextID string
+}
+
+// Alignment is the character-level timing sent alongside an audio chunk.
+// Times are in milliseconds relative to the start of the chunk's generation.
+type Alignment struct {
+	Chars            []string `json:"chars"`
+	CharStartTimesMs []int    `json:"charStartTimesMs"`
+	CharDurationsMs  []int    `json:"charDurationsMs"`
 }
 
 type DoneEvent struct {
 	IsFinal bool
+	// ContextID is the finished context on a multi-context connection.
+	ContextID string
 }
 
 type ErrorEvent struct {
 	Message string
+	// ContextID is the context the error belongs to on a multi-context connection, if any.
+	ContextID string
+}
+
+func (e ErrorEvent) Error() string {
+	return "elevenlabs tts: " + e.Message
 }
 
 type StreamEventHandler func(ctx context.Context, event StreamEvent)
@@ -60,11 +87,25 @@ type StreamEventHandler func(ctx context.Context, event StreamEvent)
 type Conn struct {
 	logger transcripts.Logger
 	conn   transcripts.WebSocketConn
+
+	dialDuration time.Duration
+	latency      transcripts.LatencyObserver
+
+	// multiContext is set by ConnectMultiContext; contextInit is sent with the first message of
+	// every context.
+	multiContext bool
+	contextInit  contextMessage
+
+	// pending holds the read started by the readiness check until ReadEvent takes it.
+	pending chan readResult
 }
 
 type connectOption struct {
-	dialer transcripts.WebSocketDialer
-	logger transcripts.Logger
+	dialer       transcripts.WebSocketDialer
+	middleware   []transcripts.WebSocketMiddleware
+	logger       transcripts.Logger
+	latency      transcripts.LatencyObserver
+	readyTimeout time.Duration
 }
 
 type ConnectOption func(*connectOption)
@@ -75,6 +116,14 @@ func WithDialer(dialer transcripts.WebSocketDialer) ConnectOption {
 	}
 }
 
+// WithWebSocketMiddleware adds middlewares for the connection. They run inside the ones of
+// ClientConfig.WebSocketMiddleware.
+func WithWebSocketMiddleware(middlewares ...transcripts.WebSocketMiddleware) ConnectOption {
+	return func(opts *connectOption) {
+		opts.middleware = append(opts.middleware, middlewares...)
+	}
+}
+
 func WithLogger(logger transcripts.Logger) ConnectOption {
 	return func(opts *connectOption) {
 		opts.logger = logger
@@ -83,32 +132,19 @@ func WithLogger(logger transcripts.Logger) ConnectOption {
 
 // ConnectRealtime opens an interactive websocket TTS session.
 // Unlike StreamAudio, this supports incremental text input and event-based audio output.
+// The server does not acknowledge the initialization message but rejects a bad model, voice or
+// setting with an error event or a close status right away. That rejection arrives with the first
+// ReadEvent. With WithReadyTimeout, ConnectRealtime waits for it and returns it as an error instead.
 func (c *Client) ConnectRealtime(ctx context.Context, req StreamInputRequest, opts ...ConnectOption) (*Conn, error) {
-	connectOpts := connectOption{
-		dialer: transcripts.DefaultDialer(),
-		logger: transcripts.NopLogger{},
-	}
-	for _, opt := range opts {
-		opt(&connectOpts)
-	}
-
-	uri, err := c.streamInputURL(req)
+	uri, err := c.streamInputURL(req, "stream-input")
 	if err != nil {
 		return nil, err
 	}
-
-	headers := http.Header{}
-	headers.Set("xi-api-key", c.config.authKey)
-	wsConn, err := connectOpts.dialer.Dial(ctx, uri, headers)
+	conn, readyTimeout, err := c.dialRealtime(ctx, uri, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	conn := &Conn{
-		logger: connectOpts.logger,
-		conn:   wsConn,
-	}
-
 	initMessage := struct {
 		Text                            string                           `json:"text"`
 		XIAPIKey                        string                           `json:"xi_api_key"`
@@ -130,17 +166,60 @@ func (c *Client) ConnectRealtime(ctx context.Context, req StreamInputRequest, op
 		_ = conn.Close()
 		return nil, err
 	}
+	if err = conn.awaitReady(ctx, readyTimeout); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
 
 	return conn, nil
 }
 
+// dialRealtime dials uri with the API key and the configured middlewares. It also returns the
+// ready timeout of opts.
+func (c *Client) dialRealtime(ctx context.Context, uri string, opts []ConnectOption) (*Conn, time.Duration, error) {
+	connectOpts := connectOption{
+		dialer:  transcripts.DefaultDialer(),
+		logger:  c.logger(),
+		latency: c.config.LatencyObserver,
+	}
+	for _, opt := range opts {
+		opt(&connectOpts)
+	}
+	connectOpts.dialer = transcripts.ChainDialer(connectOpts.dialer, slices.Concat(c.config.WebSocketMiddleware, connectOpts.middleware)...)
+
+	logURL := "<invalid url>"
+	if u, err := url.Parse(uri); err == nil {
+		logURL = transcripts.RedactURL(u)
+	}
+	headers := http.Header{}
+	headers.Set("xi-api-key", c.config.authKey)
+	dialStart := time.Now()
+	wsConn, err := connectOpts.dialer.Dial(ctx, uri, headers)
+	if err != nil {
+		transcripts.LogAttrs(ctx, connectOpts.logger, slog.LevelDebug, "websocket dial failed",
+			slog.String("url", logURL), slog.Duration("duration", time.Since(dialStart)), slog.Any("error", err))
+		return nil, 0, err
+	}
+
+	conn := &Conn{
+		logger:       connectOpts.logger,
+		conn:         wsConn,
+		dialDuration: time.Since(dialStart),
+		latency:      connectOpts.latency,
+	}
+	conn.observeLatency(ctx, transcripts.LatencyMeasurement{Metric: transcripts.LatencyDial, Duration: conn.dialDuration})
+	conn.log(ctx, slog.LevelDebug, "websocket connected", slog.String("url", logURL), slog.Duration("duration", conn.dialDuration))
+	return conn, connectOpts.readyTimeout, nil
+}
+
 // ConnectStreamInput is kept for backward compatibility.
 // Deprecated: use ConnectRealtime for interactive websocket TTS streaming.
 func (c *Client) ConnectStreamInput(ctx context.Context, req StreamInputRequest, opts ...ConnectOption) (*Conn, error) {
 	return c.ConnectRealtime(ctx, req, opts...)
 }
 
-func (c *Client) streamInputURL(req StreamInputRequest) (string, error) {
+// streamInputURL returns the websocket URL of endpoint, stream-input or multi-stream-input.
+func (c *Client) streamInputURL(req StreamInputRequest, endpoint string) (string, error) {
 	base := strings.TrimRight(c.config.BaseURL, "/")
 	if strings.HasPrefix(base, "https://") {
 		base = "wss://" + strings.TrimPrefix(base, "https://")
@@ -150,7 +229,7 @@ func (c *Client) streamInputURL(req StreamInputRequest) (string, error) {
 		base = "wss://" + strings.TrimPrefix(base, "/")
 	}
 
-	u, err := url.Parse(base + "/v1/text-to-speech/" + req.VoiceID + "/stream-input")
+	u, err := url.Parse(base + "/v1/text-to-speech/" + req.VoiceID + "/" + endpoint)
 	if err != nil {
 		return "", err
 	}
@@ -199,7 +278,22 @@ func (c *Conn) Send(ctx context.Context, msg any) error {
 }
 
 func (c *Conn) ReadEvent(ctx context.Context) (StreamEvent, error) {
-	messageType, data, err := c.conn.ReadMessage(ctx)
+	var (
+		messageType transcripts.MessageType
+		data        []byte
+		err         error
+	)
+	if c.pending != nil {
+		select {
+		case result := <-c.pending:
+			c.pending = nil
+			messageType, data, err = result.messageType, result.data, result.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		messageType, data, err = c.conn.ReadMessage(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -213,7 +307,33 @@ type Streamer struct {
 	ctx      context.Context
 	conn     *Conn
 	handlers []StreamEventHandler
+	readers  []*AudioReader
 	errCh    chan error
+
+	keepAlive time.Duration
+	state     atomic.Int32
+	lastSend  atomic.Int64
+	done      chan struct{}
+	runErr    error // set before done is closed
+
+	mu          sync.Mutex
+	interrupted bool
+	delivered   deliveryStats
+	dropped     int
+	sentChars   int // non-space characters sent with Send
+	alignChars  int // non-space characters in the alignment of received audio
+	cutoff      int // sentChars at the last Interrupt
+
+	// writeMu orders a context's first message before the others on a multi-context connection.
+	writeMu         sync.Mutex
+	context         string // guarded by mu
+	contextOpen     bool   // guarded by mu
+	contextSeq      int    // guarded by mu
+	droppedContexts map[string]bool
+
+	firstText        time.Time
+	audioSeen        bool
+	timeToFirstAudio time.Duration
 }
 
 type RealtimeSynthesizer = Streamer
@@ -225,6 +345,7 @@ func NewRealtimeSynthesizer(ctx context.Context, conn *Conn, handlers ...StreamE
 		conn:     conn,
 		handlers: handlers,
 		errCh:    make(chan error, 1),
+		done:     make(chan struct{}),
 	}
 }
 
@@ -235,8 +356,23 @@ func NewStreamer(ctx context.Context, conn *Conn, handlers ...StreamEventHandler
 }
 
 func (s *Streamer) Start() {
+	s.state.CompareAndSwap(int32(StreamerStateIdle), int32(StreamerStateStreaming))
+	s.lastSend.Store(time.Now().UnixNano())
+	if s.keepAlive > 0 {
+		go s.keepAliveLoop()
+	}
 	go func() {
 		err := s.run()
+		s.runErr = err
+		for state := s.state.Load(); state != int32(StreamerStateClosed); state = s.state.Load() {
+			if s.state.CompareAndSwap(state, int32(StreamerStateDone)) {
+				break
+			}
+		}
+		close(s.done)
+		for _, reader := range s.readers {
+			reader.finish(err)
+		}
 		if err != nil {
 			s.errCh <- err
 		}
@@ -248,7 +384,33 @@ func (s *Streamer) Err() <-chan error {
 	return s.errCh
 }
 
+// Send sends one message. It returns ErrInputClosed after CloseInput.
 func (s *Streamer) Send(msg StreamTextMessage) error {
+	if s.State() >= StreamerStateInputClosed {
+		return ErrInputClosed
+	}
+	if msg.Text != "" {
+		s.resume(msg.Text)
+	}
+	s.markText(msg.Text)
+	return s.write(msg)
+}
+
+func (s *Streamer) write(msg StreamTextMessage) error {
+	s.lastSend.Store(time.Now().UnixNano())
+	if !s.conn.multiContext {
+		return s.conn.Send(s.ctx, msg)
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	id, err := s.openContext()
+	if err != nil {
+		return err
+	}
+	if msg.Text == "" && msg.Flush != nil && *msg.Flush {
+		return s.conn.FlushContext(s.ctx, id)
+	}
+	msg.ContextID = id
 	return s.conn.Send(s.ctx, msg)
 }
 
@@ -268,13 +430,28 @@ func (s *Streamer) Flush() error {
 	})
 }
 
-// CloseInput closes the logical text input side of the realtime websocket session.
-// It currently sends a final flush frame and keeps reading output events until completion.
+// CloseInput sends the end-of-stream frame, an empty text. The server generates audio for the
+// remaining text, sends the final event and closes the session. Use Wait to wait for it.
+// On a multi-context connection it flushes the current context and sends close_socket instead.
 func (s *Streamer) CloseInput() error {
-	return s.Flush()
+	if !s.state.CompareAndSwap(int32(StreamerStateStreaming), int32(StreamerStateInputClosed)) &&
+		!s.state.CompareAndSwap(int32(StreamerStateIdle), int32(StreamerStateInputClosed)) {
+		return nil
+	}
+	if !s.conn.multiContext {
+		return s.write(StreamTextMessage{Text: ""})
+	}
+	flush := true
+	if err := s.write(StreamTextMessage{Flush: &flush}); err != nil {
+		return err
+	}
+	return s.conn.Send(s.ctx, contextMessage{CloseSocket: true})
 }
 
+// Close closes the connection with a normal closure status. Use Shutdown to let the
+// remaining audio arrive first.
 func (s *Streamer) Close() error {
+	s.state.Store(int32(StreamerStateClosed))
 	return s.conn.Close()
 }
 
@@ -297,30 +474,55 @@ func (s *Streamer) run() error {
 			}
 			return err
 		}
+		if e, ok := event.(ErrorEvent); ok {
+			s.conn.log(s.ctx, slog.LevelWarn, "websocket error event", slog.String("event_type", "error"), slog.String("error", e.Message))
+		} else {
+			s.conn.log(s.ctx, slog.LevelDebug, "websocket event", slog.String("event_type", streamEventType(event)))
+		}
+		if !s.deliver(event) {
+			continue
+		}
+		s.observeAudio(event)
 		for _, handler := range s.handlers {
 			handler(s.ctx, event)
 		}
-		if done, ok := event.(DoneEvent); ok && done.IsFinal {
+		if done, ok := event.(DoneEvent); ok && done.IsFinal && s.finalEvent(done) {
 			return nil
 		}
 	}
 }
 
+func streamEventType(event StreamEvent) string {
+	switch event.(type) {
+	case AudioEvent:
+		return "audio"
+	case DoneEvent:
+		return "final"
+	case ErrorEvent:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
 func unmarshalStreamEvent(data []byte) (StreamEvent, error) {
 	var probe struct {
-		Audio   string `json:"audio"`
-		IsFinal bool   `json:"isFinal"`
-		Error   string `json:"error"`
-		Message string `json:"message"`
+		Audio               string     `json:"audio"`
+		IsFinal             bool       `json:"isFinal"`
+		Error               string     `json:"error"`
+		Message             string     `json:"message"`
+		Alignment           *Alignment `json:"alignment"`
+		NormalizedAlignment *Alignment `json:"normalizedAlignment"`
+		ContextID           string     `json:"contextId"`
 	}
 	if err := json.Unmarshal(data, &probe); err != nil {
 		return nil, err
 	}
 	if probe.Error != "" {
-		return ErrorEvent{Message: probe.Error}, nil
+		return ErrorEvent{Message: probe.Error, ContextID: probe.ContextID}, nil
 	}
 	if probe.Message != "" && probe.Audio == "" && !probe.IsFinal {
-		return ErrorEvent{Message: probe.Message}, nil
+		return ErrorEvent{Message: probe.Message, ContextID: probe.ContextID}, nil
 	}
 	if probe.Audio != "" {
 		audio, err := base64.StdEncoding.DecodeString(probe.Audio)
@@ -328,12 +530,15 @@ func unmarshalStreamEvent(data []byte) (StreamEvent, error) {
 			return nil, err
 		}
 		return AudioEvent{
-			Audio:   audio,
-			IsFinal: probe.IsFinal,
+			Audio:               audio,
+			IsFinal:             probe.IsFinal,
+			Alignment:           probe.Alignment,
+			NormalizedAlignment: probe.NormalizedAlignment,
+			ContextID:           probe.ContextID,
 		}, nil
 	}
 	if probe.IsFinal {
-		return DoneEvent{IsFinal: true}, nil
+		return DoneEvent{IsFinal: true, ContextID: probe.ContextID}, nil
 	}
 	return nil, errors.New("unknown stream event")
 }
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/gouyuwang/go-elevenlabs/transcripts"
 )
 
 func TestClientConnectRealtimeAndReceiveAudio(t *testing.T) {
@@ -372,10 +374,102 @@ func TestRealtimeSynthesizerHelperMethods(t *testing.T) {
 	if got, want := messages[4].Text, ""; got != want {
 		t.Fatalf("messages[4].Text = %q, want %q", got, want)
 	}
-	if messages[5].Flush == nil || !*messages[5].Flush {
-		t.Fatal("messages[5] should be close-input flush")
+	if messages[5].Flush != nil {
+		t.Fatal("messages[5] should be the end-of-stream frame without flush")
 	}
 	if got, want := messages[5].Text, ""; got != want {
 		t.Fatalf("messages[5].Text = %q, want %q", got, want)
 	}
 }
+
+func TestUnmarshalStreamEventParsesAlignment(t *testing.T) {
+	t.Parallel()
+
+	event, err := unmarshalStreamEvent([]byte(`{
+		"audio":"aGVsbG8=",
+		"isFinal":false,
+		"alignment":{"chars":["H","i"],"charStartTimesMs":[0,120],"charDurationsMs":[120,80]},
+		"normalizedAlignment":{"chars":["H","i"],"charStartTimesMs":[0,110],"charDurationsMs":[110,90]}
+	}`))
+	if err != nil {
+		t.Fatalf("unmarshalStreamEvent() error = %v", err)
+	}
+	audioEvent, ok := event.(AudioEvent)
+	if !ok {
+		t.Fatalf("event type = %T, want AudioEvent", event)
+	}
+	if audioEvent.Alignment == nil || audioEvent.NormalizedAlignment == nil {
+		t.Fatal("missing alignment data")
+	}
+	if got, want := audioEvent.Alignment.CharStartTimesMs[1], 120; got != want {
+		t.Fatalf("Alignment.CharStartTimesMs[1] = %d, want %d", got, want)
+	}
+	if got, want := audioEvent.NormalizedAlignment.CharDurationsMs[1], 90; got != want {
+		t.Fatalf("NormalizedAlignment.CharDurationsMs[1] = %d, want %d", got, want)
+	}
+}
+
+func TestClientConnectRealtimeRunsWebSocketMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant")
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept websocket: %v", err)
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		if _, _, err = conn.Read(r.Context()); err != nil {
+			return
+		}
+		_ = conn.Write(r.Context(), websocket.MessageText, []byte(`{"isFinal":true}`))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var writes, reads int
+	cfg := DefaultConfig("test-key")
+	cfg.BaseURL = "http://egress.invalid"
+	cfg.WebSocketMiddleware = []transcripts.WebSocketMiddleware{{
+		Dial: func(ctx context.Context, rawURL string, header http.Header, next transcripts.DialFunc) (transcripts.WebSocketConn, error) {
+			header.Set("X-Tenant", "acme")
+			return next(ctx, strings.Replace(rawURL, "ws://egress.invalid", "ws"+strings.TrimPrefix(server.URL, "http"), 1), header)
+		},
+		Write: func(ctx context.Context, messageType transcripts.MessageType, data []byte, next transcripts.WriteFunc) error {
+			mu.Lock()
+			writes++
+			mu.Unlock()
+			return next(ctx, messageType, data)
+		},
+		Read: func(ctx context.Context, next transcripts.ReadFunc) (transcripts.MessageType, []byte, error) {
+			messageType, data, err := next(ctx)
+			if err == nil {
+				mu.Lock()
+				reads++
+				mu.Unlock()
+			}
+			return messageType, data, err
+		},
+	}}
+	conn, err := NewClientWithConfig(cfg).ConnectRealtime(context.Background(), StreamInputRequest{VoiceID: "voice_123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamer := NewRealtimeSynthesizer(context.Background(), conn)
+	streamer.Start()
+	if err = streamer.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = streamer.Close()
+
+	if got, want := tenant, "acme"; got != want {
+		t.Errorf("X-Tenant = %q, want %q", got, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if writes != 1 || reads != 1 {
+		t.Errorf("writes = %d, reads = %d, want 1 and 1", writes, reads)
+	}
+}